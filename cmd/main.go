@@ -5,21 +5,29 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"justycrawler/internal/app/crawler"
 	"justycrawler/internal/config"
+	"justycrawler/internal/domain"
 	"justycrawler/internal/fetcher"
+	"justycrawler/internal/metrics"
 	"justycrawler/internal/parser"
+	"justycrawler/internal/report"
+	"justycrawler/internal/robots"
+	"justycrawler/internal/sitemap"
 	"justycrawler/internal/state"
 	"justycrawler/internal/storage"
 )
 
 const (
-	shutdownTimeout = 5 * time.Second
+	shutdownTimeout    = 5 * time.Second
+	metricsReadTimeout = 10 * time.Second
 )
 
 func main() {
@@ -59,7 +67,17 @@ func run() error {
 	}()
 
 	// 4. Инициализация зависимостей
-	pageStorage, err := storage.NewMongoStorage(ctx, cfg.Mongo.URI, cfg.Mongo.Database, cfg.Mongo.Collection)
+	// Метрики остаются nil, если HTTP-сервер метрик не включен.
+	var crawlMetrics *metrics.Metrics
+	if cfg.Metrics.Addr != "" {
+		crawlMetrics = metrics.New()
+		stopMetrics := serveMetrics(cfg.Metrics.Addr, crawlMetrics, logger)
+		defer stopMetrics()
+	}
+
+	pageStorage, err := storage.NewMongoStorage(
+		ctx, cfg.Mongo.URI, cfg.Mongo.Database, cfg.Mongo.Collection, cfg.Mongo.RunsCollection, crawlMetrics,
+	)
 	if err != nil {
 		return fmt.Errorf("не удалось подключиться к MongoDB: %w", err)
 	}
@@ -71,7 +89,13 @@ func run() error {
 		}
 	}()
 
-	pageState, err := state.NewRedisState(ctx, cfg.Redis.Addr, cfg.Redis.Password, cfg.Redis.DB, cfg.Redis.SetKey)
+	if cfg.Mode == config.ModeServer {
+		return serve(ctx, cfg, logger, pageStorage, crawlMetrics)
+	}
+
+	pageState, err := state.NewRedisState(
+		ctx, cfg.Redis.Addr, cfg.Redis.Password, cfg.Redis.DB, cfg.Redis.SetKey, crawlMetrics,
+	)
 	if err != nil {
 		return fmt.Errorf("не удалось подключиться к Redis: %w", err)
 	}
@@ -81,41 +105,388 @@ func run() error {
 		}
 	}()
 
+	pageFrontier, closeFrontier, err := newFrontier(ctx, cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := closeFrontier(); closeErr != nil {
+			logger.Error("Не удалось корректно закрыть фронтир", slog.Any("error", closeErr))
+		}
+	}()
+	crawlMetrics.WatchFrontier(pageFrontier.Len)
+
 	if cfg.ForceRecrawl {
 		logger.Info("Флаг --force-recrawl установлен. Очистка состояния в Redis...")
 		if err := pageState.Clear(ctx); err != nil {
 			return fmt.Errorf("не удалось очистить состояние в Redis: %w", err)
 		}
+		if err := pageFrontier.Clear(ctx); err != nil {
+			return fmt.Errorf("не удалось очистить фронтир: %w", err)
+		}
 		logger.Info("Состояние успешно очищено.")
 	}
+	// Инкрементальный обход проходит все страницы заново, поэтому начинается с пустого
+	// стейта; загружаются и разбираются только изменившиеся страницы. С revisit стейт
+	// хранит время посещений, и какие URL пора обойти, решает он, а не очистка.
+	if cfg.Incremental && !cfg.Resume {
+		if !cfg.Revisit.Enabled() {
+			logger.Info("Инкрементальный обход: очистка состояния в Redis...")
+			if err := pageState.Clear(ctx); err != nil {
+				return fmt.Errorf("не удалось очистить состояние в Redis: %w", err)
+			}
+		}
+		// С --retry_failed фронтир не очищаем: в нем очередь неудачных задач,
+		// которую обход вернет в работу перед стартом.
+		if !cfg.RetryFailed {
+			if err := pageFrontier.Clear(ctx); err != nil {
+				return fmt.Errorf("не удалось очистить фронтир: %w", err)
+			}
+		}
+	}
 
-	pageFetcher := fetcher.New(cfg.HTTP.Timeout)
-	pageParser := parser.New()
+	// В режиме check-links битые ссылки копятся в отчете.
+	var linkReport *report.Writer
+	if cfg.Mode == config.ModeCheckLinks {
+		if linkReport, err = report.New(cfg.CheckLinks.Format); err != nil {
+			return err
+		}
+	}
 
 	// 5. Инициализация и запуск основной логики
-	cr := crawler.NewCrawler(
+	cr, err := newCrawler(cfg, logger, crawlerDeps{
+		storage:  pageStorage,
+		state:    pageState,
+		frontier: pageFrontier,
+		history:  pageStorage,
+		metrics:  crawlMetrics,
+		report:   linkReport,
+	})
+	if err != nil {
+		return err
+	}
+
+	run := &domain.Run{
+		JobID:     cfg.JobID,
+		Mode:      cfg.Mode,
+		WorkerID:  cfg.WorkerID,
+		StartURLs: cfg.StartURLs,
+		Config:    cfg.Snapshot(),
+		Status:    domain.RunRunning,
+		StartedAt: time.Now(),
+	}
+	if err := pageStorage.SaveRun(ctx, run); err != nil {
+		return err
+	}
+
+	// По job_id можно найти результаты запуска, а заданный явно — продолжить обход (--resume).
+	logger.Info("Краулер запускается...", slog.String("job_id", cfg.JobID),
+		slog.Bool("shared_keys", cfg.SharedKeys), slog.Any("config", cfg))
+
+	result, err := cr.Run(ctx, cfg.StartURLs)
+	finishRun(run, result, err, ctx.Err() != nil)
+	// Контекст уже может быть отменен сигналом, поэтому запись сохраняем с отдельным таймаутом.
+	saveCtx, saveCancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer saveCancel()
+	if saveErr := pageStorage.SaveRun(saveCtx, run); saveErr != nil {
+		logger.Error("Не удалось сохранить итоги запуска", slog.Any("error", saveErr))
+	}
+
+	if err != nil && !errors.Is(err, context.Canceled) {
+		logger.Error("Краулер завершился с ошибкой", slog.Any("error", err))
+		return err
+	}
+
+	summary := []any{
+		slog.Int64("pages", result.Pages),
+		slog.Int64("bytes", result.Bytes),
+		slog.Duration("duration", result.Duration),
+	}
+	switch {
+	case errors.Is(ctx.Err(), context.Canceled):
+		logger.Info("Работа была прервана сигналом завершения.", summary...)
+	case result.StopReason != "":
+		logger.Info("Обход остановлен по лимиту, оставшиеся задачи остались во фронтире.",
+			append(summary, slog.String("limit", result.StopReason))...)
+	default:
+		logger.Info("Работа успешно завершена.", summary...)
+	}
+
+	// Отчет пишем и после прерывания: найденные битые ссылки не должны теряться.
+	if linkReport != nil {
+		logger.Info("Проверка ссылок завершена.", slog.Int("broken", linkReport.Len()))
+		if err := writeReport(linkReport, cfg.CheckLinks.Output); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// finishRun записывает в run итоги запуска. interrupted — обход прерван сигналом завершения.
+func finishRun(run *domain.Run, result crawler.Result, err error, interrupted bool) {
+	run.FinishedAt = time.Now()
+	run.Stats = domain.RunStats{
+		Pages:      result.Pages,
+		Bytes:      result.Bytes,
+		Duration:   result.Duration,
+		StopReason: result.StopReason,
+	}
+
+	switch {
+	case err != nil && !errors.Is(err, context.Canceled):
+		run.Status = domain.RunFailed
+		run.Error = err.Error()
+	case interrupted:
+		run.Status = domain.RunInterrupted
+	case result.StopReason != "":
+		run.Status = domain.RunStopped
+	default:
+		run.Status = domain.RunCompleted
+	}
+}
+
+// crawlerDeps — хранилища обхода. В режиме server стейт и фронтир у каждого задания свои.
+type crawlerDeps struct {
+	storage  crawler.Storage
+	state    crawler.State
+	frontier crawler.Frontier
+	history  crawler.History // Прошлые результаты; нужны только с incremental.
+	metrics  *metrics.Metrics
+	report   *report.Writer // Отчет о битых ссылках; только в режиме check-links.
+}
+
+// newCrawler собирает краулер по настройкам cfg.
+func newCrawler(cfg *config.Config, logger *slog.Logger, deps crawlerDeps) (*crawler.Crawler, error) {
+	pageFetcher := fetcher.New(cfg.HTTP.Timeout, cfg.Robots.UserAgent, fetcher.RetryPolicy{
+		MaxAttempts: cfg.HTTP.Retry.MaxAttempts,
+		BaseDelay:   cfg.HTTP.Retry.BaseDelay,
+		MaxDelay:    cfg.HTTP.Retry.MaxDelay,
+	}, deps.metrics)
+	pageParser := parser.New()
+
+	// Интерфейс оставляем nil, если проверка robots.txt выключена.
+	var pageRobots crawler.Robots
+	if cfg.Robots.Enabled {
+		pageRobots = robots.NewChecker(cfg.HTTP.Timeout, cfg.Robots.UserAgent, cfg.Robots.CacheTTL)
+	}
+
+	var pageSitemaps crawler.Sitemaps
+	if cfg.Sitemap.Enabled {
+		pageSitemaps = sitemap.NewLoader(cfg.HTTP.Timeout, cfg.Robots.UserAgent)
+	}
+
+	hostLimits := make(map[string]crawler.HostLimits, len(cfg.Politeness.Hosts))
+	for _, h := range cfg.Politeness.Hosts {
+		hostLimits[strings.ToLower(h.Host)] = crawler.HostLimits{Delay: h.Delay, MaxConcurrency: h.MaxConcurrency}
+	}
+
+	// Оценка задач нужна только фронтиру с порядком priority.
+	var score crawler.ScoreFunc
+	if cfg.Frontier.Order == config.OrderPriority {
+		var err error
+		if score, err = newScore(cfg.Frontier.ScoreRules); err != nil {
+			return nil, err
+		}
+	}
+
+	// Без настроек повторного обхода каждый URL обходится один раз.
+	var revisit crawler.RevisitFunc
+	if cfg.Revisit.Enabled() {
+		var err error
+		if revisit, err = newRevisit(cfg.Revisit); err != nil {
+			return nil, err
+		}
+	}
+
+	var history crawler.History
+	if cfg.Incremental {
+		history = deps.history
+	}
+
+	// В режиме check-links ссылки проверяет тот же HTTP-клиент.
+	var linkCheck crawler.LinkCheck
+	if deps.report != nil {
+		linkCheck = crawler.LinkCheck{Checker: pageFetcher, Report: deps.report}
+	}
+
+	crawlerOpts := crawler.Options{
+		WorkerCount: cfg.WorkerCount,
+		MaxDepth:    cfg.MaxDepth,
+		Scope: crawler.Scope{
+			Mode:       cfg.Scope,
+			ExtraHosts: cfg.ScopeHosts,
+		},
+		Resume:      cfg.Resume,
+		RetryFailed: cfg.RetryFailed,
+		Distributed: cfg.Distributed,
+		WorkerID:    cfg.WorkerID,
+		SitemapOnly: cfg.Sitemap.Only,
+		Politeness: crawler.Politeness{
+			Delay:          cfg.Politeness.Delay,
+			MaxConcurrency: cfg.Politeness.MaxConcurrency,
+			Hosts:          hostLimits,
+		},
+		Content: crawler.Content{
+			StoreHTML:       cfg.Content.StoreHTML,
+			MaxHTMLSize:     cfg.Content.MaxHTMLSize,
+			ExtractMetadata: cfg.Content.ExtractMetadata,
+			MaxTextSize:     cfg.Content.MaxTextSize,
+		},
+		Limits: crawler.Limits{
+			MaxPages:        cfg.Limits.MaxPages,
+			MaxDuration:     cfg.Limits.MaxDuration,
+			MaxPagesPerHost: cfg.Limits.MaxPagesPerHost,
+			MaxBytes:        cfg.Limits.MaxBytes,
+		},
+		Score:            score,
+		Filter:           newFilter(cfg.Filter),
+		FollowLinks:      linkKinds(cfg.Links.Follow),
+		DedupCanonical:   cfg.DedupCanonical,
+		UserAgent:        cfg.Robots.UserAgent,
+		IgnoreDirectives: cfg.Robots.IgnoreDirectives,
+		StripParams:      cfg.Normalize.StripParams,
+		LinkCheck:        linkCheck,
+		Metrics:          deps.metrics,
+		JobID:            cfg.JobID,
+		Revisit:          revisit,
+		History:          history,
+	}
+
+	cr, err := crawler.NewCrawler(
 		logger,
-		cfg.WorkerCount,
-		cfg.MaxDepth,
-		cfg.SameHost,
+		crawlerOpts,
 		pageFetcher,
 		pageParser,
-		pageStorage,
-		pageState,
+		deps.storage,
+		deps.state,
+		deps.frontier,
+		pageRobots,
+		pageSitemaps,
 	)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось создать краулер: %w", err)
+	}
+	return cr, nil
+}
 
-	logger.Info("Краулер запускается...", slog.Any("config", cfg))
+// writeReport записывает отчет о битых ссылках в файл output или, если он не задан, в stdout.
+func writeReport(linkReport *report.Writer, output string) error {
+	if output == "" {
+		return linkReport.Render(os.Stdout)
+	}
 
-	if err := cr.Run(ctx, cfg.StartURL); err != nil && !errors.Is(err, context.Canceled) {
-		logger.Error("Краулер завершился с ошибкой", slog.Any("error", err))
+	file, err := os.Create(output)
+	if err != nil {
+		return fmt.Errorf("не удалось создать файл отчета: %w", err)
+	}
+	if err := linkReport.Render(file); err != nil {
+		_ = file.Close()
 		return err
 	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("не удалось записать файл отчета: %w", err)
+	}
+	return nil
+}
+
+// serveMetrics запускает HTTP-сервер с /metrics. Возвращается функция остановки.
+func serveMetrics(addr string, m *metrics.Metrics, logger *slog.Logger) func() {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m.Handler())
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: metricsReadTimeout}
+
+	go func() {
+		logger.Info("Метрики доступны по HTTP.", slog.String("addr", addr))
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			// Без метрик обход продолжается: это наблюдение, а не работа.
+			logger.Error("Сервер метрик остановился", slog.Any("error", err))
+		}
+	}()
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			logger.Error("Не удалось корректно остановить сервер метрик", slog.Any("error", err))
+		}
+	}
+}
 
-	if errors.Is(ctx.Err(), context.Canceled) {
-		logger.Info("Работа была прервана сигналом завершения.")
-	} else {
-		logger.Info("Работа успешно завершена.")
+// newFrontier выбирает хранилище очереди обхода. Вторым значением возвращается функция закрытия.
+func newFrontier(
+	ctx context.Context,
+	cfg *config.Config,
+	logger *slog.Logger,
+) (crawler.Frontier, func() error, error) {
+	order := domain.Ordering(cfg.Frontier.Order)
+	if cfg.Frontier.Backend == config.FrontierMemory {
+		return crawler.NewMemoryFrontier(order), func() error { return nil }, nil
 	}
 
-	return nil
+	redisFrontier, err := state.NewRedisFrontier(
+		ctx, cfg.Redis.Addr, cfg.Redis.Password, cfg.Redis.DB, cfg.Frontier.KeyPrefix, cfg.Frontier.VisibilityTimeout,
+		order, logger,
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("не удалось подключить фронтир в Redis: %w", err)
+	}
+	return redisFrontier, redisFrontier.Close, nil
+}
+
+// newScore строит оценку задач для порядка priority.
+func newScore(cfg []config.ScoreRule) (crawler.ScoreFunc, error) {
+	rules := make([]crawler.ScoreRule, 0, len(cfg))
+	for _, r := range cfg {
+		rules = append(rules, crawler.ScoreRule{Target: r.Target, Pattern: r.Pattern, Boost: r.Boost})
+	}
+	score, err := crawler.NewRuleScore(rules)
+	if err != nil {
+		return nil, fmt.Errorf("некорректные правила оценки: %w", err)
+	}
+	return score, nil
+}
+
+// newRevisit строит политику повторного обхода.
+func newRevisit(cfg config.Revisit) (crawler.RevisitFunc, error) {
+	rules := make([]crawler.RevisitRule, 0, len(cfg.Rules))
+	for _, r := range cfg.Rules {
+		rules = append(rules, crawler.RevisitRule{Pattern: r.Pattern, Interval: r.Interval})
+	}
+	revisit, err := crawler.NewRevisit(crawler.Revisit{
+		Interval:          cfg.Interval,
+		Rules:             rules,
+		SitemapChangeFreq: cfg.SitemapChangeFreq,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("некорректные правила повторного обхода: %w", err)
+	}
+	return revisit, nil
+}
+
+func newFilter(cfg config.Filter) crawler.Filter {
+	rules := make([]crawler.FilterRule, 0, len(cfg.Rules))
+	for _, r := range cfg.Rules {
+		rules = append(rules, crawler.FilterRule{
+			Name:    r.Name,
+			Action:  r.Action,
+			Target:  r.Target,
+			Pattern: r.Pattern,
+			Syntax:  r.Syntax,
+		})
+	}
+
+	return crawler.Filter{
+		Rules:          rules,
+		SkipExtensions: cfg.SkipExtensions,
+		MaxURLLength:   cfg.MaxURLLength,
+	}
+}
+
+func linkKinds(names []string) []domain.LinkKind {
+	kinds := make([]domain.LinkKind, 0, len(names))
+	for _, name := range names {
+		kinds = append(kinds, domain.LinkKind(strings.TrimSpace(strings.ToLower(name))))
+	}
+	return kinds
 }
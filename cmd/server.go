@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"justycrawler/internal/app/crawler"
+	"justycrawler/internal/config"
+	"justycrawler/internal/metrics"
+	"justycrawler/internal/server"
+	"justycrawler/internal/state"
+	"justycrawler/internal/storage"
+)
+
+// serve запускает HTTP API заданий и ждет отмены ctx. Выполняющиеся задания
+// при остановке ставятся на паузу и восстанавливаются при следующем запуске.
+func serve(
+	ctx context.Context,
+	cfg *config.Config,
+	logger *slog.Logger,
+	pageStorage *storage.MongoStorage,
+	m *metrics.Metrics,
+) error {
+	newEnv := func(ctx context.Context, jobCfg *config.Config) (server.Environment, error) {
+		return newJobEnvironment(ctx, jobCfg, logger, pageStorage, m)
+	}
+	manager := server.NewManager(ctx, cfg, newEnv, pageStorage, logger)
+	if err := manager.Restore(ctx); err != nil {
+		return err
+	}
+	// У каждого задания свой фронтир, метрика показывает их сумму.
+	m.WatchFrontier(manager.FrontierSize)
+
+	httpServer := &http.Server{Addr: cfg.Server.Addr, Handler: manager.Handler(), ReadHeaderTimeout: metricsReadTimeout}
+	serveErr := make(chan error, 1)
+	go func() {
+		logger.Info("API заданий доступен по HTTP.", slog.String("addr", cfg.Server.Addr))
+		serveErr <- httpServer.ListenAndServe()
+	}()
+
+	var err error
+	select {
+	case <-ctx.Done():
+	case listenErr := <-serveErr:
+		err = fmt.Errorf("сервер API остановился: %w", listenErr)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if shutdownErr := httpServer.Shutdown(shutdownCtx); shutdownErr != nil {
+		logger.Error("Не удалось корректно остановить сервер API", slog.Any("error", shutdownErr))
+	}
+	manager.Shutdown()
+	logger.Info("Сервер остановлен, выполнявшиеся задания поставлены на паузу.")
+	return err
+}
+
+// jobEnvironment — стейт и фронтир одного задания в режиме server.
+type jobEnvironment struct {
+	cfg           *config.Config
+	logger        *slog.Logger
+	storage       crawler.Storage
+	history       crawler.History
+	metrics       *metrics.Metrics
+	state         *state.RedisState
+	frontier      crawler.Frontier
+	closeFrontier func() error
+}
+
+func newJobEnvironment(
+	ctx context.Context,
+	cfg *config.Config,
+	logger *slog.Logger,
+	pageStorage *storage.MongoStorage,
+	m *metrics.Metrics,
+) (*jobEnvironment, error) {
+	pageState, err := state.NewRedisState(ctx, cfg.Redis.Addr, cfg.Redis.Password, cfg.Redis.DB, cfg.Redis.SetKey, m)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось подключиться к Redis: %w", err)
+	}
+
+	pageFrontier, closeFrontier, err := newFrontier(ctx, cfg, logger)
+	if err != nil {
+		_ = pageState.Close()
+		return nil, err
+	}
+
+	return &jobEnvironment{
+		cfg:           cfg,
+		logger:        logger,
+		storage:       pageStorage,
+		history:       pageStorage,
+		metrics:       m,
+		state:         pageState,
+		frontier:      pageFrontier,
+		closeFrontier: closeFrontier,
+	}, nil
+}
+
+// Crawler реализует интерфейс server.Environment.
+func (e *jobEnvironment) Crawler(resume bool) (*crawler.Crawler, error) {
+	cfg := *e.cfg
+	cfg.Resume = resume
+	return newCrawler(&cfg, e.logger, crawlerDeps{
+		storage:  e.storage,
+		state:    e.state,
+		frontier: e.frontier,
+		history:  e.history,
+		metrics:  e.metrics,
+	})
+}
+
+// FrontierLen реализует интерфейс server.Environment.
+func (e *jobEnvironment) FrontierLen(ctx context.Context) (int, error) {
+	return e.frontier.Len(ctx)
+}
+
+// Clear реализует интерфейс server.Environment.
+func (e *jobEnvironment) Clear(ctx context.Context, keepState bool) error {
+	if !keepState {
+		if err := e.state.Clear(ctx); err != nil {
+			return fmt.Errorf("не удалось очистить состояние задания: %w", err)
+		}
+	}
+	if err := e.frontier.Clear(ctx); err != nil {
+		return fmt.Errorf("не удалось очистить фронтир задания: %w", err)
+	}
+	return nil
+}
+
+// Close реализует интерфейс server.Environment.
+func (e *jobEnvironment) Close() error {
+	return errors.Join(e.state.Close(), e.closeFrontier())
+}
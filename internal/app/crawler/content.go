@@ -0,0 +1,55 @@
+package crawler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"unicode/utf8"
+)
+
+// Content — что сохранять со страницы, кроме ссылок.
+type Content struct {
+	StoreHTML       bool // Сохранять исходный HTML в сжатом виде.
+	MaxHTMLSize     int  // Сколько байт HTML сохранять; хвост длиннее отбрасывается.
+	ExtractMetadata bool // Сохранять заголовок, описание, заголовки h1–h6, язык и текст.
+	// MaxTextSize — сколько байт видимого текста сохранять, чтобы документ большой
+	// страницы не превысил лимит MongoDB в 16 МБ; 0 — без ограничения.
+	MaxTextSize int
+}
+
+// truncateText обрезает текст до limit байт (если limit > 0) по границе символа.
+// Второе значение сообщает, был ли текст обрезан.
+func truncateText(text string, limit int) (string, bool) {
+	if limit <= 0 || len(text) <= limit {
+		return text, false
+	}
+	text = text[:limit]
+	// Срез мог разрезать многобайтовый символ — отбрасываем его хвост.
+	for text != "" {
+		if r, size := utf8.DecodeLastRuneInString(text); r != utf8.RuneError || size > 1 {
+			break
+		}
+		text = text[:len(text)-1]
+	}
+	return text, true
+}
+
+// compressHTML обрезает HTML до limit байт (если limit > 0) и сжимает его gzip.
+// Второе значение сообщает, была ли страница обрезана.
+func compressHTML(html []byte, limit int) ([]byte, bool, error) {
+	truncated := false
+	if limit > 0 && len(html) > limit {
+		html = html[:limit]
+		truncated = true
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(html); err != nil {
+		return nil, false, fmt.Errorf("не удалось сжать HTML: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, false, fmt.Errorf("не удалось сжать HTML: %w", err)
+	}
+	return buf.Bytes(), truncated, nil
+}
@@ -2,14 +2,19 @@ package crawler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/url"
+	"strings"
 	"sync"
 	"time"
 
 	"justycrawler/internal/domain"
+	"justycrawler/internal/metrics"
+	"justycrawler/internal/robots"
+	"justycrawler/internal/urlnorm"
 
 	"golang.org/x/sync/errgroup"
 )
@@ -18,137 +23,411 @@ const (
 	storageTimeout = 10 * time.Second
 )
 
-// Task представляет собой задачу для краулера.
-type Task struct {
-	URL       string
-	Depth     int
-	ParentURL string
+// Options — настройки обхода.
+type Options struct {
+	WorkerCount int
+	MaxDepth    int
+	Scope       Scope
+	Resume      bool // Продолжить прерванный обход с задач, сохраненных во фронтире.
+	// RetryFailed перед обходом возвращает в очередь URL, которые не удалось
+	// обработать в прошлых запусках.
+	RetryFailed bool
+	// Distributed включает совместный обход несколькими процессами с общим фронтиром:
+	// экземпляр подключается к уже идущему обходу, а не требует пустой фронтир.
+	Distributed bool
+	WorkerID    string
+	Politeness  Politeness
+	Content     Content
+	Filter      Filter
+	Limits      Limits
+	// Score проставляет задачам Task.Priority перед добавлением во фронтир.
+	// Нужен фронтиру с порядком domain.OrderPriority; nil — оценка не считается.
+	Score ScoreFunc
+	// FollowLinks — виды ссылок, по которым идет обход; остальные только
+	// записываются. Пусто — только навигационные.
+	FollowLinks []domain.LinkKind
+	// SitemapOnly обходит только URL из sitemap, не переходя по ссылкам страниц.
+	SitemapOnly bool
+	// DedupCanonical не обходит ссылки со страниц, чей canonical указывает
+	// на другой уже известный URL: такие страницы сохраняются как дубликаты.
+	DedupCanonical bool
+	// UserAgent — имя робота, которому адресованы директивы вида «googlebot: noindex».
+	UserAgent string
+	// IgnoreDirectives отключает rel="nofollow", meta robots и X-Robots-Tag,
+	// например для внутренних аудитов.
+	IgnoreDirectives bool
+	// StripParams — query-параметры, удаляемые при нормализации URL («utm_*» — префикс).
+	StripParams []string
+	// LinkCheck включает проверку ссылок обойденных страниц.
+	LinkCheck LinkCheck
+	// Metrics — метрики обхода; nil — не собираются.
+	Metrics *metrics.Metrics
+	// JobID — задание, к которому относится обход; попадает в логи и результаты.
+	JobID string
+	// Revisit задает, через сколько URL снова можно обойти; nil — каждый URL
+	// обходится один раз.
+	Revisit RevisitFunc
+	// History включает инкрементальный обход: страницы запрашиваются условно по
+	// валидаторам прошлого обхода JobID, неизменившиеся не разбираются заново.
+	// nil — каждая страница загружается и разбирается полностью.
+	History History
 }
 
 // Crawler представляет собой веб-краулер.
 type Crawler struct {
-	logger      *slog.Logger
-	workerCount int
-	maxDepth    int
-	sameHost    bool
-	startHost   string
+	logger    *slog.Logger
+	opts      Options
+	scopes    *seedScopes
+	scheduler *hostScheduler
+	filter    *urlFilter
+	norm      *urlnorm.Normalizer
+	follow    map[domain.LinkKind]bool
+	limits    *limiter
+	// rejected помнит ссылки, отброшенные фильтром, чтобы записывать каждую один раз.
+	rejected sync.Map
+	// checked — результаты проверки ссылок: URL -> *linkCheckResult.
+	checked sync.Map
 
-	fetcher Fetcher
-	parser  Parser
-	storage Storage
-	state   State
+	fetcher  Fetcher
+	parser   Parser
+	storage  Storage
+	state    State
+	frontier Frontier
+	robots   Robots
+	sitemaps Sitemaps
 }
 
 // NewCrawler инициализирует новый краулер с внедрением всех зависимостей.
+// Ошибка возвращается, если в настройках некорректные правила фильтрации
+// или неизвестный вид ссылок.
 func NewCrawler(
 	logger *slog.Logger,
-	workerCount, maxDepth int,
-	sameHost bool,
+	opts Options,
 	fetcher Fetcher,
 	parser Parser,
 	storage Storage,
 	state State,
-) *Crawler {
-	return &Crawler{
-		logger:      logger,
-		workerCount: workerCount,
-		maxDepth:    maxDepth,
-		sameHost:    sameHost,
-		fetcher:     fetcher,
-		parser:      parser,
-		storage:     storage,
-		state:       state,
+	frontier Frontier,
+	robots Robots,
+	sitemaps Sitemaps,
+) (*Crawler, error) {
+	filter, err := newURLFilter(opts.Filter)
+	if err != nil {
+		return nil, fmt.Errorf("некорректные правила фильтрации: %w", err)
+	}
+	follow, err := followSet(opts.FollowLinks)
+	if err != nil {
+		return nil, err
 	}
+	if opts.SitemapOnly && sitemaps == nil {
+		return nil, errors.New("для обхода только по sitemap нужен источник sitemap")
+	}
+	if opts.LinkCheck.Checker != nil && opts.LinkCheck.Report == nil {
+		return nil, errors.New("для проверки ссылок нужен отчет о битых ссылках")
+	}
+
+	if opts.WorkerID != "" {
+		logger = logger.With(slog.String("worker_id", opts.WorkerID))
+	}
+	if opts.JobID != "" {
+		logger = logger.With(slog.String("job_id", opts.JobID))
+	}
+
+	return &Crawler{
+		logger:    logger,
+		opts:      opts,
+		scheduler: newHostScheduler(opts.Politeness),
+		filter:    filter,
+		norm:      urlnorm.New(opts.StripParams),
+		follow:    follow,
+		limits:    newLimiter(opts.Limits),
+		fetcher:   fetcher,
+		parser:    parser,
+		storage:   storage,
+		state:     state,
+		frontier:  frontier,
+		robots:    robots,
+		sitemaps:  sitemaps,
+	}, nil
 }
 
-func (c *Crawler) Run(ctx context.Context, startURL string) error {
-	parsedStartURL, err := url.Parse(startURL)
+// Run обходит сайты, начиная со стартовых URL. У каждого стартового URL своя
+// область обхода: ссылки проверяются по области того сида, с которого начался путь.
+// Обход идет, пока не кончатся задачи или не сработает один из лимитов.
+// Run вызывается один раз: для продолжения обхода создается новый Crawler с Resume.
+func (c *Crawler) Run(ctx context.Context, startURLs []string) (Result, error) {
+	c.limits.begin()
+	defer c.limits.finish()
+
+	seeds, err := c.normalizeSeeds(startURLs)
+	if err != nil {
+		return Result{}, err
+	}
+	c.scopes, err = newSeedScopes(c.opts.Scope, seeds)
 	if err != nil {
-		return fmt.Errorf("невалидный стартовый URL %s: %w", startURL, err)
+		return Result{}, err
+	}
+
+	if err := c.prepareFrontier(ctx); err != nil {
+		return Result{}, err
+	}
+	if err := c.seed(ctx, seeds); err != nil {
+		return Result{}, err
+	}
+
+	if c.opts.Limits.MaxDuration > 0 {
+		timer := time.AfterFunc(c.opts.Limits.MaxDuration, func() {
+			c.limits.stop(StopMaxDuration)
+		})
+		defer timer.Stop()
 	}
-	c.startHost = parsedStartURL.Host
 
-	tasks := make(chan Task, c.workerCount)
+	// Канал без буфера: задачи копятся во фронтире, а не в канале, поэтому
+	// воркер, нашедший новые ссылки, никогда не блокируется на их отправке.
+	tasks := make(chan domain.Task)
+	d := newDispatcher(c.frontier, tasks, c.logger, c.limits.done())
 
 	g, ctx := errgroup.WithContext(ctx)
-	wg := &sync.WaitGroup{}
 
-	for range c.workerCount {
+	c.opts.Metrics.AddWorkers(c.opts.WorkerCount)
+	defer c.opts.Metrics.AddWorkers(-c.opts.WorkerCount)
+	for range c.opts.WorkerCount {
 		g.Go(func() error {
-			for {
-				select {
-				case task, ok := <-tasks:
-					if !ok {
-						return nil
-					}
-					c.processTask(ctx, task, tasks, wg)
-				case <-ctx.Done():
-					return ctx.Err()
-				}
+			for task := range tasks {
+				c.processTask(ctx, task)
+				d.taskFinished()
 			}
+			return nil
 		})
 	}
 
-	added, err := c.state.Add(ctx, startURL)
+	g.Go(func() error {
+		return d.run(ctx)
+	})
+
+	err = g.Wait()
+	return c.limits.result(), err
+}
+
+// Progress возвращает итоги обхода на текущий момент. Безопасен для вызова
+// из других горутин во время Run.
+func (c *Crawler) Progress() Result {
+	return c.limits.result()
+}
+
+// prepareFrontier проверяет, что во фронтире нет хвостов прошлого обхода, или,
+// в режиме resume, возвращает в очередь задачи, которые были в работе при остановке.
+// С RetryFailed в очередь возвращаются и задачи, завершившиеся ошибкой.
+func (c *Crawler) prepareFrontier(ctx context.Context) error {
+	if c.opts.Distributed && !c.opts.Resume {
+		// Хвосты во фронтире — это работа других экземпляров, а зависшие задачи
+		// вернутся в очередь сами по истечении аренды.
+		return c.retryFailed(ctx)
+	}
+
+	if c.opts.Resume {
+		requeued, err := c.frontier.Requeue(ctx)
+		if err != nil {
+			return fmt.Errorf("не удалось вернуть незавершенные задачи в очередь: %w", err)
+		}
+		c.logger.InfoContext(ctx, "Продолжаем прерванный обход.", slog.Int("requeued", requeued))
+		return c.retryFailed(ctx)
+	}
+
+	pending, err := c.frontier.Len(ctx)
 	if err != nil {
-		close(tasks)
-		return fmt.Errorf("не удалось добавить стартовый URL в стейт: %w", err)
+		return fmt.Errorf("не удалось получить размер фронтира: %w", err)
+	}
+	if pending > 0 {
+		return fmt.Errorf("во фронтире осталось %d задач прошлого обхода: "+
+			"продолжите его флагом --resume или начните заново с --force_recrawl", pending)
+	}
+	return c.retryFailed(ctx)
+}
+
+// normalizeSeeds приводит стартовые URL к единому виду и убирает повторы.
+func (c *Crawler) normalizeSeeds(startURLs []string) ([]string, error) {
+	seeds := make([]string, 0, len(startURLs))
+	seen := make(map[string]struct{}, len(startURLs))
+	for _, startURL := range startURLs {
+		seed, err := c.norm.Normalize(startURL)
+		if err != nil {
+			return nil, fmt.Errorf("невалидный стартовый URL %s: %w", startURL, err)
+		}
+		if _, ok := seen[seed]; ok {
+			continue
+		}
+		seen[seed] = struct{}{}
+		seeds = append(seeds, seed)
+	}
+	if len(seeds) == 0 {
+		return nil, errors.New("не задано ни одного стартового URL")
 	}
+	return seeds, nil
+}
 
-	if added {
-		c.logger.InfoContext(ctx, "Добавляем стартовую задачу в очередь.", slog.String("url", startURL))
-		wg.Add(1)
-		tasks <- Task{URL: startURL, Depth: 0, ParentURL: ""}
-	} else {
-		c.logger.InfoContext(ctx, "Стартовый URL уже был обработан ранее, новых задач нет.")
+// seed ставит в очередь стартовые URL и, если включено, URL из sitemap.
+func (c *Crawler) seed(ctx context.Context, seeds []string) error {
+	if !c.opts.SitemapOnly {
+		for _, seed := range seeds {
+			if err := c.seedStart(ctx, seed); err != nil {
+				return err
+			}
+		}
+	}
+	if c.sitemaps != nil {
+		return c.seedSitemaps(ctx, seeds)
 	}
+	return nil
+}
 
-	g.Go(func() error {
-		wg.Wait()
-		close(tasks)
+func (c *Crawler) seedStart(ctx context.Context, seed string) error {
+	task := domain.Task{URL: seed, Depth: 0, ParentURL: "", Seed: seed}
+	added, err := c.state.Add(ctx, seed, c.staleBefore(task))
+	if err != nil {
+		return fmt.Errorf("не удалось добавить стартовый URL в стейт: %w", err)
+	}
+
+	if !added {
+		c.logger.InfoContext(ctx, "Стартовый URL уже был обработан ранее.", slog.String("url", seed))
 		return nil
-	})
+	}
 
-	return g.Wait()
+	c.logger.InfoContext(ctx, "Добавляем стартовую задачу в очередь.", slog.String("url", seed))
+	if err := c.push(ctx, task); err != nil {
+		return fmt.Errorf("не удалось добавить стартовую задачу во фронтир: %w", err)
+	}
+	return nil
 }
 
-func (c *Crawler) processTask(ctx context.Context, task Task, tasks chan<- Task, wg *sync.WaitGroup) {
-	defer wg.Done()
-
+func (c *Crawler) processTask(ctx context.Context, task domain.Task) {
 	log := c.logger.With(slog.String("url", task.URL), slog.Int("depth", task.Depth))
 	log.InfoContext(ctx, "Обработка страницы")
+	defer c.opts.Metrics.WorkerBusy()()
 
-	body, err := c.fetcher.Fetch(ctx, task.URL)
-	if err != nil {
-		log.ErrorContext(ctx, "Не удалось загрузить страницу", slog.Any("error", err))
+	err := c.visit(ctx, task, log)
+	if ctx.Err() != nil || errors.Is(err, errLimitReached) {
+		// Прерванная задача остается «в работе» и вернется в очередь при resume.
 		return
 	}
-	defer body.Close()
 
-	htmlBytes, err := io.ReadAll(body)
 	if err != nil {
-		log.ErrorContext(ctx, "Не удалось прочитать тело ответа", slog.Any("error", err))
+		failure := classifyFailure(task, err)
+		if failure.Class == domain.ErrorParse {
+			c.opts.Metrics.Error(metrics.StageParse)
+		} else {
+			c.opts.Metrics.Error(metrics.StageFetch)
+		}
+		log.ErrorContext(ctx, "Не удалось обработать страницу",
+			slog.String("class", string(failure.Class)), slog.Any("error", err))
+		c.handleFailed(ctx, task, failure)
+
+		task.Attempts = failure.Attempts
+		if failErr := c.frontier.Fail(ctx, task, err.Error()); failErr != nil {
+			log.ErrorContext(ctx, "Не удалось отметить задачу как неудачную", slog.Any("error", failErr))
+		}
 		return
 	}
 
-	links, err := c.parser.ParseLinks(task.URL, htmlBytes)
+	if doneErr := c.frontier.Done(ctx, task); doneErr != nil {
+		log.ErrorContext(ctx, "Не удалось отметить задачу как выполненную", slog.Any("error", doneErr))
+	}
+}
+
+func (c *Crawler) visit(ctx context.Context, task domain.Task, log *slog.Logger) error {
+	if !c.allowedByRobots(ctx, task, log) {
+		return nil
+	}
+	reserved, err := c.reservePage(ctx, task, log)
+	if err != nil || !reserved {
+		return err
+	}
+
+	previous, validators := c.previous(ctx, task, log)
+	result, htmlBytes, err := c.download(ctx, task.URL, validators)
 	if err != nil {
-		log.ErrorContext(ctx, "Не удалось распарсить страницу", slog.Any("error", err))
-		return
+		return fmt.Errorf("не удалось загрузить страницу: %w", err)
+	}
+	if isUnchanged(previous, result, htmlBytes) {
+		c.handleUnchanged(ctx, task, previous, log)
+		return nil
 	}
 
-	c.handleResult(ctx, task, links)
+	// Ресурсы (стили, изображения) сохраняем как факт загрузки, без разбора.
+	if !isHTML(result.ContentType) {
+		c.handleResult(ctx, task, result, htmlBytes, nil, domain.RobotsDirectives{})
+		return nil
+	}
 
-	if task.Depth >= c.maxDepth {
+	// После редиректов относительные ссылки нужно разрешать от конечного URL.
+	page, err := c.parser.ParsePage(result.FinalURL, htmlBytes)
+	if err != nil {
+		return &failureError{class: domain.ErrorParse, err: fmt.Errorf("не удалось распарсить страницу: %w", err)}
+	}
+	page.Links = c.normalizeLinks(page.Links)
+	page.Canonical = c.normalizeCanonical(page.Canonical)
+	directives := c.directives(result, page)
+
+	if c.isCanonicalDuplicate(ctx, task, page, log) {
+		c.handleDuplicate(ctx, task, result, page)
+		return nil
+	}
+
+	c.handleResult(ctx, task, result, htmlBytes, page, directives)
+	if c.opts.LinkCheck.Checker != nil {
+		c.checkLinks(ctx, task, page.Links, log)
+	}
+
+	c.followLinks(ctx, task, page.Links, directives.NoFollow, log)
+	return nil
+}
+
+// followLinks ставит в очередь ссылки страницы, по которым идет обход.
+// noFollow — страница запрещает переход по своим ссылкам.
+func (c *Crawler) followLinks(
+	ctx context.Context,
+	task domain.Task,
+	links []domain.Link,
+	noFollow bool,
+	log *slog.Logger,
+) {
+	if task.Depth >= c.opts.MaxDepth || c.opts.SitemapOnly {
+		return
+	}
+	if noFollow {
+		log.DebugContext(ctx, "Страница запрещает переход по ссылкам (nofollow)")
 		return
 	}
 
-	for _, link := range links {
-		if !c.shouldCrawl(link) {
+	for _, pageLink := range links {
+		if !c.follow[pageLink.Kind] {
+			continue
+		}
+		if pageLink.NoFollow() && !c.opts.IgnoreDirectives {
+			continue
+		}
+
+		link := pageLink.URL
+		parsedLink, parseErr := url.Parse(link)
+		if parseErr != nil || !c.scopes.contains(task.Seed, parsedLink) {
+			continue
+		}
+		if c.limits.hostExhausted(strings.ToLower(parsedLink.Host)) {
 			continue
 		}
 
-		added, addErr := c.state.Add(ctx, link)
+		child := domain.Task{
+			URL:        link,
+			Depth:      task.Depth + 1,
+			ParentURL:  task.URL,
+			Seed:       task.Seed,
+			AnchorText: pageLink.Text,
+		}
+		if !c.passesFilter(ctx, child, parsedLink, log) {
+			continue
+		}
+
+		added, addErr := c.state.Add(ctx, link, c.staleBefore(child))
 		if addErr != nil {
 			log.ErrorContext(ctx, "Не удалось добавить URL в стейт", slog.Any("error", addErr))
 			continue
@@ -158,22 +437,133 @@ func (c *Crawler) processTask(ctx context.Context, task Task, tasks chan<- Task,
 			continue
 		}
 
-		wg.Add(1)
-		select {
-		case tasks <- Task{URL: link, Depth: task.Depth + 1, ParentURL: task.URL}:
-		case <-ctx.Done():
-			wg.Done()
-			return
+		if pushErr := c.push(ctx, child); pushErr != nil {
+			log.ErrorContext(ctx, "Не удалось добавить задачу во фронтир",
+				slog.String("link", link), slog.Any("error", pushErr))
 		}
 	}
 }
 
-func (c *Crawler) handleResult(ctx context.Context, task Task, foundURLs []string) {
+// push оценивает задачу и добавляет ее во фронтир.
+func (c *Crawler) push(ctx context.Context, task domain.Task) error {
+	if c.opts.Score != nil {
+		task.Priority = c.opts.Score(task)
+	}
+	return c.frontier.Push(ctx, task)
+}
+
+// download загружает страницу, соблюдая ограничения нагрузки на ее хост.
+// Тело ответа вычитывается и закрывается здесь же.
+func (c *Crawler) download(
+	ctx context.Context,
+	rawURL string,
+	validators domain.Validators,
+) (*domain.FetchResult, []byte, error) {
+	pace, err := c.pace(ctx, rawURL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result, err := c.fetcher.Fetch(ctx, rawURL, validators, pace)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer result.Body.Close()
+
+	htmlBytes, err := io.ReadAll(result.Body)
+	if err != nil {
+		return nil, nil, readError(fmt.Errorf("не удалось прочитать тело ответа: %w", err))
+	}
+	c.limits.addBytes(len(htmlBytes))
+	c.opts.Metrics.PageFetched(len(htmlBytes))
+	return result, htmlBytes, nil
+}
+
+// pace возвращает ожидание очереди к хосту URL, которое загрузчик проходит перед
+// каждой попыткой, в том числе перед повторами.
+func (c *Crawler) pace(ctx context.Context, rawURL string) (domain.Pace, error) {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось распарсить URL %s: %w", rawURL, err)
+	}
+
+	delay := c.crawlDelay(ctx, rawURL)
+	return func(ctx context.Context) (func(), error) {
+		return c.scheduler.acquire(ctx, parsedURL.Host, delay)
+	}, nil
+}
+
+// crawlDelay возвращает Crawl-delay из robots.txt, если проверка robots.txt включена.
+func (c *Crawler) crawlDelay(ctx context.Context, rawURL string) time.Duration {
+	if c.robots == nil {
+		return 0
+	}
+	delay, err := c.robots.CrawlDelay(ctx, rawURL)
+	if err != nil {
+		c.logger.WarnContext(ctx, "Не удалось получить Crawl-delay",
+			slog.String("url", rawURL), slog.Any("error", err))
+		return 0
+	}
+	return delay
+}
+
+// handleResult сохраняет загруженную страницу. body — тело ответа; для ресурсов
+// (page == nil) из него считается только хеш.
+func (c *Crawler) handleResult(
+	ctx context.Context,
+	task domain.Task,
+	result *domain.FetchResult,
+	body []byte,
+	page *domain.Page,
+	directives domain.RobotsDirectives,
+) {
 	crawledData := domain.CrawledData{
-		URL:        task.URL,
-		Depth:      task.Depth,
-		FoundOn:    task.ParentURL,
-		FoundLinks: foundURLs,
+		URL:           task.URL,
+		Depth:         task.Depth,
+		FoundOn:       task.ParentURL,
+		Status:        domain.StatusCrawled,
+		Seed:          task.Seed,
+		Sitemap:       task.Sitemap,
+		JobID:         c.opts.JobID,
+		WorkerID:      c.opts.WorkerID,
+		StatusCode:    result.StatusCode,
+		FinalURL:      result.FinalURL,
+		RedirectChain: result.RedirectChain,
+		ContentType:   result.ContentType,
+		ContentLength: result.ContentLength,
+		Headers:       result.Header,
+		FetchDuration: result.Duration,
+		ETag:          result.Header.Get("ETag"),
+		LastModified:  result.Header.Get("Last-Modified"),
+		ContentHash:   contentHash(body),
+		LastChecked:   time.Now(),
+		NoIndex:       directives.NoIndex,
+		NoFollow:      directives.NoFollow,
+	}
+
+	// У ресурсов (page == nil) нет ни ссылок, ни содержимого для сохранения.
+	if page != nil {
+		crawledData.FoundLinks = linkURLs(page.Links)
+		crawledData.Links = page.Links
+		crawledData.Canonical = page.Canonical
+	}
+
+	// Страницу с noindex сохраняем только как факт обхода, без содержимого.
+	storeContent := page != nil && !directives.NoIndex
+	if c.opts.Content.ExtractMetadata && storeContent {
+		stored := *page
+		stored.Text, stored.TextTruncated = truncateText(page.Text, c.opts.Content.MaxTextSize)
+		crawledData.Page = &stored
+	}
+	if c.opts.Content.StoreHTML && storeContent {
+		compressed, truncated, err := compressHTML(body, c.opts.Content.MaxHTMLSize)
+		if err != nil {
+			c.logger.ErrorContext(ctx, "Не удалось подготовить HTML к сохранению",
+				slog.String("url", task.URL), slog.Any("error", err))
+		} else {
+			crawledData.HTML = compressed
+			crawledData.HTMLTruncated = truncated
+		}
 	}
 
 	saveCtx, cancel := context.WithTimeout(ctx, storageTimeout)
@@ -185,13 +575,219 @@ func (c *Crawler) handleResult(ctx context.Context, task Task, foundURLs []strin
 	}
 }
 
-func (c *Crawler) shouldCrawl(link string) bool {
-	if !c.sameHost {
+// isCanonicalDuplicate сообщает, что canonical страницы указывает на другой URL,
+// который уже есть в стейте. Новый canonical, наоборот, добавляется в стейт:
+// его содержимое уже получено с этой страницы, и загружать его повторно незачем.
+func (c *Crawler) isCanonicalDuplicate(ctx context.Context, task domain.Task, page *domain.Page, log *slog.Logger) bool {
+	if !c.opts.DedupCanonical || page.Canonical == "" || page.Canonical == task.URL {
+		return false
+	}
+
+	added, err := c.state.Add(ctx, page.Canonical, c.staleBefore(task))
+	if err != nil {
+		log.ErrorContext(ctx, "Не удалось проверить canonical в стейте", slog.Any("error", err))
+		return false
+	}
+	if !added {
+		log.DebugContext(ctx, "Canonical страницы уже известен, ссылки не обходим",
+			slog.String("canonical", page.Canonical))
+	}
+	return !added
+}
+
+// handleDuplicate сохраняет страницу-дубликат: ответ сервера и ссылку на canonical,
+// без ссылок и содержимого.
+func (c *Crawler) handleDuplicate(ctx context.Context, task domain.Task, result *domain.FetchResult, page *domain.Page) {
+	duplicateData := domain.CrawledData{
+		URL:           task.URL,
+		Depth:         task.Depth,
+		FoundOn:       task.ParentURL,
+		Status:        domain.StatusDuplicate,
+		Seed:          task.Seed,
+		Sitemap:       task.Sitemap,
+		JobID:         c.opts.JobID,
+		WorkerID:      c.opts.WorkerID,
+		StatusCode:    result.StatusCode,
+		FinalURL:      result.FinalURL,
+		RedirectChain: result.RedirectChain,
+		ContentType:   result.ContentType,
+		ContentLength: result.ContentLength,
+		Headers:       result.Header,
+		FetchDuration: result.Duration,
+		Canonical:     page.Canonical,
+	}
+
+	saveCtx, cancel := context.WithTimeout(ctx, storageTimeout)
+	defer cancel()
+
+	if err := c.storage.Save(saveCtx, duplicateData); err != nil {
+		c.logger.ErrorContext(ctx, "Не удалось сохранить дубликат",
+			slog.String("url", task.URL), slog.Any("error", err))
+	}
+}
+
+// reservePage учитывает загрузку страницы в лимитах. Если бюджет хоста исчерпан,
+// URL сохраняется как пропущенный и возвращается false.
+func (c *Crawler) reservePage(ctx context.Context, task domain.Task, log *slog.Logger) (bool, error) {
+	parsedURL, err := url.Parse(task.URL)
+	if err != nil {
+		return false, fmt.Errorf("не удалось распарсить URL %s: %w", task.URL, err)
+	}
+
+	reserved, err := c.limits.reservePage(strings.ToLower(parsedURL.Host))
+	if err != nil {
+		return false, err
+	}
+	if !reserved {
+		log.DebugContext(ctx, "Бюджет страниц хоста исчерпан")
+		c.handleSkipped(ctx, task, skipReasonHostBudget)
+	}
+	return reserved, nil
+}
+
+// allowedByRobots проверяет robots.txt. Запрещенные страницы сохраняются как пропущенные,
+// чтобы по результатам обхода было видно, что именно закрыл сайт.
+func (c *Crawler) allowedByRobots(ctx context.Context, task domain.Task, log *slog.Logger) bool {
+	if c.robots == nil {
 		return true
 	}
-	parsedLink, err := url.Parse(link)
+
+	allowed, err := c.robots.Allowed(ctx, task.URL)
 	if err != nil {
+		log.ErrorContext(ctx, "Не удалось проверить robots.txt", slog.Any("error", err))
 		return false
 	}
-	return parsedLink.Host == c.startHost
+	if !allowed {
+		log.InfoContext(ctx, "Страница запрещена robots.txt, пропускаем")
+		c.handleSkipped(ctx, task, domain.SkipReasonRobots)
+	}
+	return allowed
+}
+
+func (c *Crawler) handleSkipped(ctx context.Context, task domain.Task, reason string) {
+	skippedData := domain.CrawledData{
+		URL:        task.URL,
+		Depth:      task.Depth,
+		FoundOn:    task.ParentURL,
+		Status:     domain.StatusSkipped,
+		Seed:       task.Seed,
+		Sitemap:    task.Sitemap,
+		SkipReason: reason,
+		JobID:      c.opts.JobID,
+		WorkerID:   c.opts.WorkerID,
+	}
+
+	saveCtx, cancel := context.WithTimeout(ctx, storageTimeout)
+	defer cancel()
+
+	if err := c.storage.Save(saveCtx, skippedData); err != nil {
+		c.logger.ErrorContext(ctx, "Не удалось сохранить пропущенный URL",
+			slog.String("url", task.URL), slog.Any("error", err))
+	}
+}
+
+// normalizeLinks приводит ссылки к единому виду, чтобы и стейт, и хранилище
+// работали с одним ключом для одной страницы. Повторы после нормализации убираются.
+func (c *Crawler) normalizeLinks(links []domain.Link) []domain.Link {
+	type key struct {
+		url  string
+		kind domain.LinkKind
+	}
+
+	normalized := make([]domain.Link, 0, len(links))
+	seen := make(map[key]struct{}, len(links))
+	for _, link := range links {
+		norm, err := c.norm.Normalize(link.URL)
+		if err != nil {
+			continue
+		}
+		k := key{url: norm, kind: link.Kind}
+		if _, ok := seen[k]; ok {
+			continue
+		}
+		seen[k] = struct{}{}
+		link.URL = norm
+		normalized = append(normalized, link)
+	}
+	return normalized
+}
+
+// normalizeCanonical приводит canonical к виду ключей стейта; некорректный отбрасывается.
+func (c *Crawler) normalizeCanonical(canonical string) string {
+	if canonical == "" {
+		return ""
+	}
+	norm, err := c.norm.Normalize(canonical)
+	if err != nil {
+		return ""
+	}
+	return norm
+}
+
+// directives объединяет meta robots страницы и заголовок X-Robots-Tag.
+func (c *Crawler) directives(result *domain.FetchResult, page *domain.Page) domain.RobotsDirectives {
+	if c.opts.IgnoreDirectives {
+		return domain.RobotsDirectives{}
+	}
+	header := robots.ParseXRobotsTag(result.Header.Values("X-Robots-Tag"), c.opts.UserAgent)
+	return page.Directives.Merge(header)
+}
+
+// followSet строит множество видов ссылок, по которым идет обход.
+func followSet(kinds []domain.LinkKind) (map[domain.LinkKind]bool, error) {
+	if len(kinds) == 0 {
+		kinds = []domain.LinkKind{domain.LinkNavigation}
+	}
+	set := make(map[domain.LinkKind]bool, len(kinds))
+	for _, kind := range kinds {
+		switch kind {
+		case domain.LinkNavigation, domain.LinkForm, domain.LinkResource, domain.LinkEmbed, domain.LinkRedirect:
+			set[kind] = true
+		default:
+			return nil, fmt.Errorf("неизвестный вид ссылок %q, допустимо: navigation, form, resource, embed, redirect", kind)
+		}
+	}
+	return set, nil
+}
+
+// isHTML сообщает, стоит ли разбирать ответ как HTML. Без Content-Type
+// считаем ответ страницей, как и раньше.
+func isHTML(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	return mediaType == "text/html" || mediaType == "application/xhtml+xml"
+}
+
+// linkURLs возвращает адреса ссылок без повторов: один адрес может встретиться
+// в разных видах ссылок.
+func linkURLs(links []domain.Link) []string {
+	urls := make([]string, 0, len(links))
+	seen := make(map[string]struct{}, len(links))
+	for _, link := range links {
+		if _, ok := seen[link.URL]; ok {
+			continue
+		}
+		seen[link.URL] = struct{}{}
+		urls = append(urls, link.URL)
+	}
+	return urls
+}
+
+// passesFilter применяет правила фильтрации. Отброшенная ссылка один раз
+// сохраняется как пропущенная с указанием сработавшего правила.
+func (c *Crawler) passesFilter(ctx context.Context, task domain.Task, parsedLink *url.URL, log *slog.Logger) bool {
+	allowed, reason := c.filter.check(parsedLink)
+	if allowed {
+		return true
+	}
+
+	if _, seen := c.rejected.LoadOrStore(task.URL, struct{}{}); !seen {
+		log.DebugContext(ctx, "Ссылка отброшена фильтром",
+			slog.String("link", task.URL), slog.String("reason", reason))
+		c.handleSkipped(ctx, task, reason)
+	}
+	return false
 }
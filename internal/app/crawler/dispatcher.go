@@ -0,0 +1,133 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"justycrawler/internal/domain"
+)
+
+// idlePollInterval — как часто диспетчер перепроверяет фронтир, если задач нет,
+// а сигнал от воркеров не пришел (например, задачи добавил другой процесс).
+const idlePollInterval = time.Second
+
+// dispatcher забирает задачи из фронтира и раздает их воркерам. Это единственное место,
+// где читается фронтир, поэтому воркеры только пишут в него и не ждут друг друга.
+type dispatcher struct {
+	frontier Frontier
+	tasks    chan<- domain.Task
+	logger   *slog.Logger
+	stop     <-chan struct{} // Закрывается, когда обход пора остановить по лимиту.
+
+	inFlight atomic.Int64  // Задачи, отданные воркерам и еще не завершенные.
+	wake     chan struct{} // Сигнал «воркер закончил задачу — во фронтире могло что-то появиться».
+}
+
+func newDispatcher(frontier Frontier, tasks chan<- domain.Task, logger *slog.Logger, stop <-chan struct{}) *dispatcher {
+	return &dispatcher{
+		frontier: frontier,
+		tasks:    tasks,
+		logger:   logger,
+		stop:     stop,
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// run раздает задачи, пока фронтир не опустеет и все воркеры не освободятся
+// или пока не сработает лимит. По выходе закрывает канал задач, чтобы воркеры
+// доделали начатое и завершились; оставшиеся задачи сохраняются во фронтире.
+func (d *dispatcher) run(ctx context.Context) error {
+	defer close(d.tasks)
+
+	for {
+		if d.stopped() {
+			return nil
+		}
+
+		task, ok, err := d.frontier.Pop(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			d.logger.ErrorContext(ctx, "Не удалось получить задачу из фронтира", slog.Any("error", err))
+		}
+
+		if ok {
+			d.inFlight.Add(1)
+			select {
+			case d.tasks <- task:
+				continue
+			case <-d.stop:
+				// Задача уже взята из очереди и останется «в работе» до resume.
+				d.inFlight.Add(-1)
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		finished, err := d.finished(ctx)
+		if err != nil {
+			return err
+		}
+		if finished {
+			return nil
+		}
+
+		if err := d.waitForWork(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+// taskFinished вызывается воркером после обработки задачи. К этому моменту
+// все найденные на странице ссылки уже записаны во фронтир.
+func (d *dispatcher) taskFinished() {
+	d.inFlight.Add(-1)
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// finished сообщает, что работы больше нет: свободны все воркеры и во фронтире пусто.
+func (d *dispatcher) finished(ctx context.Context) (bool, error) {
+	if d.inFlight.Load() > 0 {
+		return false, nil
+	}
+
+	pending, err := d.frontier.Len(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return false, ctx.Err()
+		}
+		return false, fmt.Errorf("не удалось получить размер фронтира: %w", err)
+	}
+	return pending == 0, nil
+}
+
+func (d *dispatcher) waitForWork(ctx context.Context) error {
+	timer := time.NewTimer(idlePollInterval)
+	defer timer.Stop()
+
+	select {
+	case <-d.wake:
+	case <-timer.C:
+	case <-d.stop:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+func (d *dispatcher) stopped() bool {
+	select {
+	case <-d.stop:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,196 @@
+package crawler_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"justycrawler/internal/app/crawler"
+	"justycrawler/internal/domain"
+	"justycrawler/internal/fetcher"
+	"justycrawler/internal/parser"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	stressFanOut = 12
+	stressDepth  = 3
+	// unboundedDepth не ограничивает обход глубиной: страницу могут первой найти через
+	// соседа, пока родитель еще ставит детей в очередь, и тогда ее глубина больше
+	// настоящей. Размер обхода задает сам сайт.
+	unboundedDepth = 100
+)
+
+// memoryState — стейт в памяти: каждый URL добавляется один раз.
+type memoryState struct {
+	seen sync.Map
+}
+
+func (s *memoryState) Add(_ context.Context, url string, _ time.Time) (bool, error) {
+	_, loaded := s.seen.LoadOrStore(url, struct{}{})
+	return !loaded, nil
+}
+
+func (s *memoryState) Clear(context.Context) error { return nil }
+func (s *memoryState) Close() error                { return nil }
+
+// countingStorage считает сохранения каждого URL.
+type countingStorage struct {
+	mu    sync.Mutex
+	saved map[string]int
+}
+
+func (s *countingStorage) Save(_ context.Context, data domain.CrawledData) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.saved[data.URL]++
+	return nil
+}
+
+func (s *countingStorage) Close(context.Context) error { return nil }
+
+// fanOutSite отдает дерево страниц: у каждой страницы stressFanOut детей, а кроме того
+// ссылки на корень, родителя и соседей, чтобы одни и те же URL находились много раз.
+func fanOutSite(t *testing.T) (*httptest.Server, *sync.Map) {
+	t.Helper()
+
+	var hits sync.Map
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		counter, _ := hits.LoadOrStore(r.URL.Path, new(hitCounter))
+		counter.(*hitCounter).inc()
+
+		id := strings.TrimPrefix(r.URL.Path, "/p/")
+		if r.URL.Path == "/" {
+			id = ""
+		}
+		depth := 0
+		if id != "" {
+			depth = strings.Count(id, "-") + 1
+		}
+
+		var body strings.Builder
+		body.WriteString(`<html><body><a href="/">root</a>`)
+		if depth > 0 {
+			parent := id[:max(strings.LastIndex(id, "-"), 0)]
+			fmt.Fprintf(&body, `<a href="%s">parent</a>`, pagePath(parent))
+			for sibling := range stressFanOut {
+				fmt.Fprintf(&body, `<a href="%s">sibling</a>`, pagePath(childID(parent, sibling)))
+			}
+		}
+		if depth < stressDepth {
+			for child := range stressFanOut {
+				fmt.Fprintf(&body, `<a href="%s">child</a>`, pagePath(childID(id, child)))
+			}
+		}
+		body.WriteString(`</body></html>`)
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = io.WriteString(w, body.String())
+	}))
+	t.Cleanup(server.Close)
+	return server, &hits
+}
+
+type hitCounter struct {
+	mu sync.Mutex
+	n  int
+}
+
+func (c *hitCounter) inc() {
+	c.mu.Lock()
+	c.n++
+	c.mu.Unlock()
+}
+
+func (c *hitCounter) value() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.n
+}
+
+func childID(parent string, n int) string {
+	if parent == "" {
+		return strconv.Itoa(n)
+	}
+	return parent + "-" + strconv.Itoa(n)
+}
+
+func pagePath(id string) string {
+	if id == "" {
+		return "/"
+	}
+	return "/p/" + id
+}
+
+// TestRunLargeFanOut проверяет, что обход широкого и глубокого графа с множеством
+// повторяющихся ссылок завершается и посещает каждую страницу ровно один раз.
+func TestRunLargeFanOut(t *testing.T) {
+	server, hits := fanOutSite(t)
+
+	const workers = 16
+	storage := &countingStorage{saved: make(map[string]int)}
+	c, err := crawler.NewCrawler(
+		slog.New(slog.NewTextHandler(io.Discard, nil)),
+		crawler.Options{
+			WorkerCount: workers,
+			MaxDepth:    unboundedDepth,
+			Scope:       crawler.Scope{Mode: crawler.ScopeHost},
+			Politeness:  crawler.Politeness{MaxConcurrency: workers},
+		},
+		fetcher.New(5*time.Second, "justycrawler", fetcher.RetryPolicy{MaxAttempts: 1}, nil),
+		parser.New(),
+		storage,
+		&memoryState{},
+		crawler.NewMemoryFrontier(domain.OrderBFS),
+		nil,
+		nil,
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	done := make(chan struct{})
+	var result crawler.Result
+	go func() {
+		defer close(done)
+		result, err = c.Run(ctx, []string{server.URL + "/"})
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		t.Fatal("обход не завершился за отведенное время")
+	}
+	require.NoError(t, err)
+
+	// 1 + 12 + 12² + 12³ страниц.
+	expected := 0
+	for level, width := 0, 1; level <= stressDepth; level, width = level+1, width*stressFanOut {
+		expected += width
+	}
+
+	assert.EqualValues(t, expected, result.Pages)
+	assert.Len(t, storage.saved, expected)
+	for url, n := range storage.saved {
+		assert.Equalf(t, 1, n, "страница %s сохранена %d раз", url, n)
+	}
+
+	visited := 0
+	hits.Range(func(path, counter any) bool {
+		visited++
+		assert.Equalf(t, 1, counter.(*hitCounter).value(), "страница %s загружена повторно", path)
+		return true
+	})
+	assert.Equal(t, expected, visited)
+}
@@ -0,0 +1,140 @@
+package crawler_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"justycrawler/internal/app/crawler"
+	"justycrawler/internal/domain"
+	"justycrawler/internal/fetcher"
+	"justycrawler/internal/parser"
+	"justycrawler/internal/state"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newDistributedCrawler создает экземпляр распределенного обхода со стейтом
+// и фронтиром в общем Redis.
+func newDistributedCrawler(t *testing.T, addr, workerID string, storage crawler.Storage) *crawler.Crawler {
+	t.Helper()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ctx := context.Background()
+
+	pageState, err := state.NewRedisState(ctx, addr, "", 0, "crawler:visited_urls:test", nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = pageState.Close() })
+
+	frontier, err := state.NewRedisFrontier(ctx, addr, "", 0, "crawler:frontier:test", time.Minute,
+		domain.OrderBFS, logger)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = frontier.Close() })
+
+	const workers = 4
+	c, err := crawler.NewCrawler(
+		logger,
+		crawler.Options{
+			WorkerCount: workers,
+			MaxDepth:    unboundedDepth,
+			Distributed: true,
+			WorkerID:    workerID,
+			Scope:       crawler.Scope{Mode: crawler.ScopeHost},
+			Politeness:  crawler.Politeness{MaxConcurrency: workers},
+		},
+		fetcher.New(5*time.Second, "justycrawler", fetcher.RetryPolicy{MaxAttempts: 1}, nil),
+		parser.New(),
+		storage,
+		pageState,
+		frontier,
+		nil,
+		nil,
+	)
+	require.NoError(t, err)
+	return c
+}
+
+// TestRunDistributed запускает два экземпляра на общем Redis: каждая страница
+// загружается ровно один раз, оба экземпляра берут задачи из общей очереди
+// и оба завершаются, когда она опустела.
+func TestRunDistributed(t *testing.T) {
+	server, hits := fanOutSite(t)
+	mr := miniredis.RunT(t)
+
+	const instances = 2
+	storages := make([]*countingStorage, instances)
+	crawlers := make([]*crawler.Crawler, instances)
+	for i := range crawlers {
+		storages[i] = &countingStorage{saved: make(map[string]int)}
+		crawlers[i] = newDistributedCrawler(t, mr.Addr(), fmt.Sprintf("crawler-%d", i), storages[i])
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	errs := make([]error, instances)
+	run := func(i int) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = crawlers[i].Run(ctx, []string{server.URL + "/"})
+		}()
+	}
+
+	// Второй экземпляр подключается к уже идущему обходу: к этому моменту
+	// стартовая страница взята первым и фронтир не пуст.
+	run(0)
+	require.Eventually(t, func() bool {
+		_, ok := hits.Load("/")
+		return ok
+	}, 10*time.Second, time.Millisecond)
+	for i := 1; i < instances; i++ {
+		run(i)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		t.Fatal("экземпляры не завершились после опустошения фронтира")
+	}
+	for i, err := range errs {
+		require.NoErrorf(t, err, "экземпляр %d", i)
+	}
+
+	expected := 0
+	for level, width := 0, 1; level <= stressDepth; level, width = level+1, width*stressFanOut {
+		expected += width
+	}
+
+	saved := make(map[string]int)
+	for i, storage := range storages {
+		assert.NotEmptyf(t, storage.saved, "экземпляр %d не обработал ни одной страницы", i)
+		for url, n := range storage.saved {
+			saved[url] += n
+		}
+	}
+	assert.Len(t, saved, expected)
+	for url, n := range saved {
+		assert.Equalf(t, 1, n, "страница %s сохранена %d раз", url, n)
+	}
+
+	visited := 0
+	hits.Range(func(path, counter any) bool {
+		visited++
+		assert.Equalf(t, 1, counter.(*hitCounter).value(), "страница %s загружена повторно", path)
+		return true
+	})
+	assert.Equal(t, expected, visited)
+}
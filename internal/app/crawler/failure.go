@@ -0,0 +1,102 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+
+	"justycrawler/internal/domain"
+)
+
+// failureError помечает ошибку этапа обработки, класс которой известен заранее
+// (например, ошибку разбора страницы).
+type failureError struct {
+	class domain.ErrorClass
+	err   error
+}
+
+func (e *failureError) Error() string {
+	return e.err.Error()
+}
+
+func (e *failureError) Unwrap() error {
+	return e.err
+}
+
+// Failure реализует интерфейс domain.FailureError.
+func (e *failureError) Failure() domain.Failure {
+	return domain.Failure{Class: e.class, Message: e.err.Error(), Attempts: 1}
+}
+
+// classifyFailure описывает ошибку обработки задачи для сохранения в хранилище.
+// Попытки прошлых запусков прибавляются к попыткам текущего.
+func classifyFailure(task domain.Task, err error) domain.Failure {
+	failure := domain.Failure{Class: domain.ErrorOther, Message: err.Error(), Attempts: 1}
+
+	var failureErr domain.FailureError
+	if errors.As(err, &failureErr) {
+		failure = failureErr.Failure()
+		failure.Message = err.Error()
+	} else if isTimeout(err) {
+		failure.Class = domain.ErrorTimeout
+	}
+
+	failure.Attempts = max(failure.Attempts, 1) + task.Attempts
+	return failure
+}
+
+// readError классифицирует ошибку чтения тела ответа: соединение оборвалось
+// или истек таймаут.
+func readError(err error) error {
+	if isTimeout(err) {
+		return &failureError{class: domain.ErrorTimeout, err: err}
+	}
+	return &failureError{class: domain.ErrorNetwork, err: err}
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())
+}
+
+// handleFailed сохраняет URL, который не удалось загрузить или разобрать, чтобы
+// битые ссылки можно было выбрать из хранилища вместе с причиной и страницей,
+// на которой они найдены.
+func (c *Crawler) handleFailed(ctx context.Context, task domain.Task, failure domain.Failure) {
+	failedData := domain.CrawledData{
+		URL:           task.URL,
+		Depth:         task.Depth,
+		FoundOn:       task.ParentURL,
+		Status:        domain.StatusFailed,
+		Seed:          task.Seed,
+		Sitemap:       task.Sitemap,
+		JobID:         c.opts.JobID,
+		WorkerID:      c.opts.WorkerID,
+		StatusCode:    failure.StatusCode,
+		RedirectChain: failure.RedirectChain,
+		Error:         &failure,
+	}
+
+	saveCtx, cancel := context.WithTimeout(ctx, storageTimeout)
+	defer cancel()
+
+	if err := c.storage.Save(saveCtx, failedData); err != nil {
+		c.logger.ErrorContext(ctx, "Не удалось сохранить неудачный URL",
+			slog.String("url", task.URL), slog.Any("error", err))
+	}
+}
+
+// retryFailed возвращает в очередь задачи, завершившиеся ошибкой в прошлых запусках.
+func (c *Crawler) retryFailed(ctx context.Context) error {
+	if !c.opts.RetryFailed {
+		return nil
+	}
+	retried, err := c.frontier.RetryFailed(ctx)
+	if err != nil {
+		return fmt.Errorf("не удалось вернуть неудачные задачи в очередь: %w", err)
+	}
+	c.logger.InfoContext(ctx, "Неудачные задачи возвращены в очередь.", slog.Int("retried", retried))
+	return nil
+}
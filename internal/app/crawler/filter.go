@@ -0,0 +1,173 @@
+package crawler
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Действия и цели правил фильтрации.
+const (
+	FilterInclude = "include"
+	FilterExclude = "exclude"
+
+	FilterTargetURL   = "url"
+	FilterTargetHost  = "host"
+	FilterTargetPath  = "path"
+	FilterTargetQuery = "query"
+
+	FilterSyntaxRegex = "regex"
+	FilterSyntaxGlob  = "glob"
+)
+
+// Filter — правила, по которым отбираются найденные ссылки.
+type Filter struct {
+	Rules          []FilterRule
+	SkipExtensions []string // Расширения файлов, которые не нужно загружать, например ".pdf".
+	MaxURLLength   int      // 0 — без ограничения.
+}
+
+// FilterRule — одно правило. Правила проверяются по порядку, решает первое совпавшее.
+// Если есть хотя бы одно include-правило, ссылка без совпадений отбрасывается.
+type FilterRule struct {
+	Name    string // Необязательное имя для логов и причины пропуска.
+	Action  string // include или exclude.
+	Target  string // url, host, path или query.
+	Pattern string
+	Syntax  string // regex или glob; в glob «*» — любые символы, «?» — один символ.
+}
+
+type compiledRule struct {
+	FilterRule
+	re *regexp.Regexp
+}
+
+// urlFilter — скомпилированный Filter.
+type urlFilter struct {
+	rules        []compiledRule
+	hasInclude   bool
+	skipExt      map[string]struct{}
+	maxURLLength int
+}
+
+func newURLFilter(cfg Filter) (*urlFilter, error) {
+	f := &urlFilter{
+		skipExt:      make(map[string]struct{}, len(cfg.SkipExtensions)),
+		maxURLLength: cfg.MaxURLLength,
+	}
+
+	for _, ext := range cfg.SkipExtensions {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		f.skipExt[ext] = struct{}{}
+	}
+
+	for i, rule := range cfg.Rules {
+		compiled, err := compileRule(rule)
+		if err != nil {
+			return nil, fmt.Errorf("правило фильтра №%d: %w", i+1, err)
+		}
+		if compiled.Name == "" {
+			compiled.Name = fmt.Sprintf("%s[%d] %s %s", compiled.Action, i+1, compiled.Target, compiled.Pattern)
+		}
+		f.rules = append(f.rules, compiled)
+		f.hasInclude = f.hasInclude || compiled.Action == FilterInclude
+	}
+
+	return f, nil
+}
+
+func compileRule(rule FilterRule) (compiledRule, error) {
+	if rule.Action != FilterInclude && rule.Action != FilterExclude {
+		return compiledRule{}, fmt.Errorf("неизвестное действие %q, допустимо: include, exclude", rule.Action)
+	}
+
+	switch rule.Target {
+	case FilterTargetURL, FilterTargetHost, FilterTargetPath, FilterTargetQuery:
+	case "":
+		rule.Target = FilterTargetURL
+	default:
+		return compiledRule{}, fmt.Errorf("неизвестная цель %q, допустимо: url, host, path, query", rule.Target)
+	}
+
+	expr := rule.Pattern
+	switch rule.Syntax {
+	case FilterSyntaxRegex:
+	case FilterSyntaxGlob, "":
+		rule.Syntax = FilterSyntaxGlob
+		expr = globToRegex(rule.Pattern)
+	default:
+		return compiledRule{}, fmt.Errorf("неизвестный синтаксис %q, допустимо: regex, glob", rule.Syntax)
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return compiledRule{}, fmt.Errorf("некорректный шаблон %q: %w", rule.Pattern, err)
+	}
+	return compiledRule{FilterRule: rule, re: re}, nil
+}
+
+// check решает, можно ли обходить ссылку. Если нельзя, вторым значением
+// возвращается причина: какое ограничение или правило сработало.
+func (f *urlFilter) check(link *url.URL) (bool, string) {
+	raw := link.String()
+
+	if f.maxURLLength > 0 && len(raw) > f.maxURLLength {
+		return false, fmt.Sprintf("filter: длина URL больше %d", f.maxURLLength)
+	}
+
+	ext := strings.ToLower(path.Ext(link.Path))
+	if _, skip := f.skipExt[ext]; skip {
+		return false, "filter: расширение " + ext
+	}
+
+	for _, rule := range f.rules {
+		if !rule.re.MatchString(targetValue(link, raw, rule.Target)) {
+			continue
+		}
+		if rule.Action == FilterExclude {
+			return false, "filter: " + rule.Name
+		}
+		return true, ""
+	}
+
+	if f.hasInclude {
+		return false, "filter: не подошло ни одно include-правило"
+	}
+	return true, ""
+}
+
+func targetValue(link *url.URL, raw, target string) string {
+	switch target {
+	case FilterTargetHost:
+		return link.Hostname()
+	case FilterTargetPath:
+		return link.Path
+	case FilterTargetQuery:
+		return link.RawQuery
+	default:
+		return raw
+	}
+}
+
+// globToRegex переводит glob в регулярное выражение, совпадающее со всей строкой.
+func globToRegex(glob string) string {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
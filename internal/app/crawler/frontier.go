@@ -0,0 +1,115 @@
+package crawler
+
+import (
+	"context"
+	"sync"
+
+	"justycrawler/internal/domain"
+)
+
+// MemoryFrontier — неограниченный фронтир в памяти процесса. Подходит для разовых
+// обходов, когда возобновление после перезапуска не нужно.
+type MemoryFrontier struct {
+	mu         sync.Mutex
+	order      domain.Ordering
+	queue      taskQueue
+	inProgress map[string]domain.Task
+	failed     map[string]domain.Task // URL -> задача, завершившаяся ошибкой.
+}
+
+// NewMemoryFrontier создает пустой фронтир в памяти, выдающий задачи в порядке order.
+// Пустой или неизвестный порядок означает обход в ширину.
+func NewMemoryFrontier(order domain.Ordering) *MemoryFrontier {
+	return &MemoryFrontier{
+		order:      order,
+		queue:      newTaskQueue(order),
+		inProgress: make(map[string]domain.Task),
+		failed:     make(map[string]domain.Task),
+	}
+}
+
+// Push реализует интерфейс Frontier.
+func (f *MemoryFrontier) Push(_ context.Context, task domain.Task) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.queue.push(task)
+	return nil
+}
+
+// Pop реализует интерфейс Frontier.
+func (f *MemoryFrontier) Pop(_ context.Context) (domain.Task, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	task, ok := f.queue.pop()
+	if !ok {
+		return domain.Task{}, false, nil
+	}
+	f.inProgress[task.URL] = task
+	return task, true, nil
+}
+
+// Done реализует интерфейс Frontier.
+func (f *MemoryFrontier) Done(_ context.Context, task domain.Task) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.inProgress, task.URL)
+	return nil
+}
+
+// Fail реализует интерфейс Frontier.
+func (f *MemoryFrontier) Fail(_ context.Context, task domain.Task, _ string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.inProgress, task.URL)
+	f.failed[task.URL] = task
+	return nil
+}
+
+// Requeue реализует интерфейс Frontier.
+func (f *MemoryFrontier) Requeue(_ context.Context) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	requeued := len(f.inProgress)
+	for url, task := range f.inProgress {
+		f.queue.push(task)
+		delete(f.inProgress, url)
+	}
+	return requeued, nil
+}
+
+// RetryFailed реализует интерфейс Frontier.
+func (f *MemoryFrontier) RetryFailed(_ context.Context) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	retried := len(f.failed)
+	for url, task := range f.failed {
+		f.queue.push(task)
+		delete(f.failed, url)
+	}
+	return retried, nil
+}
+
+// Len реализует интерфейс Frontier.
+func (f *MemoryFrontier) Len(_ context.Context) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.queue.len() + len(f.inProgress), nil
+}
+
+// Clear реализует интерфейс Frontier.
+func (f *MemoryFrontier) Clear(_ context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.queue = newTaskQueue(f.order)
+	f.inProgress = make(map[string]domain.Task)
+	f.failed = make(map[string]domain.Task)
+	return nil
+}
@@ -0,0 +1,69 @@
+package crawler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"justycrawler/internal/domain"
+)
+
+// previous возвращает результат прошлого обхода URL и валидаторы для условного
+// запроса. Без History или для URL, который раньше не был успешно загружен,
+// возвращаются пустые значения, и страница загружается полностью.
+func (c *Crawler) previous(
+	ctx context.Context,
+	task domain.Task,
+	log *slog.Logger,
+) (domain.CrawledData, domain.Validators) {
+	if c.opts.History == nil {
+		return domain.CrawledData{}, domain.Validators{}
+	}
+
+	lookupCtx, cancel := context.WithTimeout(ctx, storageTimeout)
+	defer cancel()
+
+	prev, found, err := c.opts.History.Previous(lookupCtx, c.opts.JobID, task.URL)
+	if err != nil {
+		log.WarnContext(ctx, "Не удалось получить прошлый результат, загружаем страницу полностью",
+			slog.Any("error", err))
+		return domain.CrawledData{}, domain.Validators{}
+	}
+	if !found || prev.Status != domain.StatusCrawled {
+		return domain.CrawledData{}, domain.Validators{}
+	}
+	return prev, domain.Validators{ETag: prev.ETag, LastModified: prev.LastModified}
+}
+
+// isUnchanged сообщает, что страница не изменилась с прошлого обхода: сервер
+// ответил 304 или, если он не поддерживает условные запросы, тело совпало по хешу.
+func isUnchanged(prev domain.CrawledData, result *domain.FetchResult, body []byte) bool {
+	if result.StatusCode == http.StatusNotModified {
+		return true
+	}
+	return prev.ContentHash != "" && prev.ContentHash == contentHash(body)
+}
+
+// handleUnchanged отмечает время проверки неизменившейся страницы и, не разбирая
+// ее заново, ставит в очередь ссылки, сохраненные в прошлом обходе.
+func (c *Crawler) handleUnchanged(ctx context.Context, task domain.Task, prev domain.CrawledData, log *slog.Logger) {
+	log.DebugContext(ctx, "Страница не изменилась с прошлого обхода")
+
+	saveCtx, cancel := context.WithTimeout(ctx, storageTimeout)
+	defer cancel()
+
+	if err := c.opts.History.MarkUnchanged(saveCtx, c.opts.JobID, task.URL, time.Now()); err != nil {
+		log.ErrorContext(ctx, "Не удалось отметить проверку страницы", slog.Any("error", err))
+	}
+
+	c.followLinks(ctx, task, prev.Links, prev.NoFollow, log)
+}
+
+// contentHash возвращает SHA-256 тела ответа в hex.
+func contentHash(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
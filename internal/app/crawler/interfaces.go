@@ -2,18 +2,37 @@ package crawler
 
 import (
 	"context"
-	"io"
+	"time"
+
 	"justycrawler/internal/domain"
 )
 
 //go:generate mockery --name Fetcher --output ../../../mocks --outpkg mocks
 type Fetcher interface {
-	Fetch(ctx context.Context, url string) (io.ReadCloser, error)
+	// Fetch загружает страницу. С непустыми validators запрос условный: если страница
+	// не менялась, возвращается результат со статусом 304 и пустым телом. pace (может
+	// быть nil) вызывается перед каждой попыткой; хост освобождается после Body.Close.
+	Fetch(ctx context.Context, url string, validators domain.Validators, pace domain.Pace) (*domain.FetchResult, error)
+}
+
+// LinkChecker проверяет, что ссылка отвечает без ошибки, не загружая ее содержимое.
+//
+//go:generate mockery --name LinkChecker --output ../../../mocks --outpkg mocks
+type LinkChecker interface {
+	// Check, как и Fetch, вызывает pace (может быть nil) перед каждой попыткой.
+	Check(ctx context.Context, url string, pace domain.Pace) error
+}
+
+// LinkReport принимает битые ссылки, найденные в режиме проверки ссылок.
+//
+//go:generate mockery --name LinkReport --output ../../../mocks --outpkg mocks
+type LinkReport interface {
+	Broken(ctx context.Context, link domain.BrokenLink) error
 }
 
 //go:generate mockery --name Parser --output ../../../mocks --outpkg mocks
 type Parser interface {
-	ParseLinks(baseRawURL string, htmlBody []byte) ([]string, error)
+	ParsePage(baseRawURL string, htmlBody []byte) (*domain.Page, error)
 }
 
 //go:generate mockery --name Storage --output ../../../mocks --outpkg mocks
@@ -22,9 +41,58 @@ type Storage interface {
 	Close(ctx context.Context) error
 }
 
+// History хранит результаты прошлых обходов для инкрементального режима.
+//
+//go:generate mockery --name History --output ../../../mocks --outpkg mocks
+type History interface {
+	// Previous возвращает сохраненный результат URL в обходе jobID.
+	// Второе значение false означает, что URL раньше не обходился.
+	Previous(ctx context.Context, jobID, url string) (domain.CrawledData, bool, error)
+	// MarkUnchanged отмечает, что страница проверена в checkedAt и не изменилась.
+	MarkUnchanged(ctx context.Context, jobID, url string, checkedAt time.Time) error
+}
+
+// State помнит, когда URL последний раз ставился в очередь.
+//
 //go:generate mockery --name State --output ../../../mocks --outpkg mocks
 type State interface {
-	Add(ctx context.Context, url string) (bool, error)
+	// Add отмечает посещение URL и возвращает true, если URL нужно обойти: его еще
+	// не было или прошлое посещение было раньше staleBefore. Нулевое staleBefore —
+	// URL обходится только один раз.
+	Add(ctx context.Context, url string, staleBefore time.Time) (bool, error)
 	Clear(ctx context.Context) error
 	Close() error
 }
+
+//go:generate mockery --name Robots --output ../../../mocks --outpkg mocks
+type Robots interface {
+	Allowed(ctx context.Context, rawURL string) (bool, error)
+	CrawlDelay(ctx context.Context, rawURL string) (time.Duration, error)
+}
+
+// Sitemaps находит sitemap сайта и возвращает перечисленные в них URL.
+//
+//go:generate mockery --name Sitemaps --output ../../../mocks --outpkg mocks
+type Sitemaps interface {
+	Discover(ctx context.Context, siteURL string) ([]domain.SitemapEntry, error)
+}
+
+// Frontier хранит очередь обхода так, чтобы ее можно было восстановить после падения.
+//
+//go:generate mockery --name Frontier --output ../../../mocks --outpkg mocks
+type Frontier interface {
+	Push(ctx context.Context, task domain.Task) error
+	// Pop забирает следующую задачу из очереди и отмечает ее как выполняемую.
+	// Второе значение false означает, что очередь пуста.
+	Pop(ctx context.Context) (domain.Task, bool, error)
+	// Done и Fail завершают задачу, полученную из Pop, вместе с ее арендой (Task.Lease).
+	Done(ctx context.Context, task domain.Task) error
+	Fail(ctx context.Context, task domain.Task, reason string) error
+	// Requeue возвращает в очередь задачи, которые были в работе при остановке.
+	Requeue(ctx context.Context) (int, error)
+	// RetryFailed возвращает в очередь задачи, завершившиеся ошибкой.
+	RetryFailed(ctx context.Context) (int, error)
+	// Len возвращает число задач в очереди и в работе.
+	Len(ctx context.Context) (int, error)
+	Clear(ctx context.Context) error
+}
@@ -0,0 +1,176 @@
+package crawler
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Причины остановки обхода по лимиту.
+const (
+	StopMaxPages    = "max_pages"
+	StopMaxDuration = "max_duration"
+	StopMaxBytes    = "max_bytes"
+)
+
+// skipReasonHostBudget — причина пропуска URL, когда бюджет его хоста исчерпан.
+const skipReasonHostBudget = "limit: max_pages_per_host"
+
+// errLimitReached означает, что задача не обработана из-за остановки по лимиту.
+// Такая задача остается во фронтире «в работе» и вернется в очередь при resume.
+var errLimitReached = errors.New("обход остановлен по лимиту")
+
+// Limits — когда останавливать обход. Нулевое значение — без ограничения.
+// Лимиты действуют в пределах одного экземпляра краулера.
+type Limits struct {
+	MaxPages        int64         // Сколько страниц загрузить всего.
+	MaxDuration     time.Duration // Сколько длится обход.
+	MaxPagesPerHost int64         // Сколько страниц загрузить с одного хоста; остальные пропускаются.
+	MaxBytes        int64         // Сколько байт тел ответов загрузить всего.
+}
+
+// Result — итоги обхода.
+type Result struct {
+	Pages    int64 // Загрузки страниц, включая неудачные.
+	Bytes    int64 // Байты загруженных тел ответов.
+	Duration time.Duration
+	// StopReason — лимит, остановивший обход (StopMaxPages и т.д.).
+	// Пусто, если обход завершился сам.
+	StopReason string
+}
+
+// limiter считает загруженные страницы и байты и сообщает диспетчеру, что пора
+// остановиться. Остановка мягкая: новые задачи не раздаются, начатые дорабатываются.
+type limiter struct {
+	cfg Limits
+
+	pages    atomic.Int64
+	bytes    atomic.Int64
+	started  atomic.Int64 // Время начала обхода в наносекундах Unix; 0 — не начат.
+	finished atomic.Int64 // Время окончания; 0 — обход идет.
+
+	hostsMu sync.Mutex
+	hosts   map[string]int64
+
+	stopOnce sync.Once
+	stopped  chan struct{}
+	reason   string // Записывается один раз до закрытия stopped.
+}
+
+func newLimiter(cfg Limits) *limiter {
+	return &limiter{
+		cfg:     cfg,
+		hosts:   make(map[string]int64),
+		stopped: make(chan struct{}),
+	}
+}
+
+// reservePage учитывает загрузку страницы хоста до того, как она начнется, чтобы
+// параллельные воркеры не вышли за лимиты. Второе значение false означает, что
+// бюджет хоста исчерпан.
+func (l *limiter) reservePage(host string) (bool, error) {
+	if l.isStopped() {
+		return false, errLimitReached
+	}
+
+	if l.cfg.MaxPagesPerHost > 0 {
+		l.hostsMu.Lock()
+		if l.hosts[host] >= l.cfg.MaxPagesPerHost {
+			l.hostsMu.Unlock()
+			return false, nil
+		}
+		l.hosts[host]++
+		l.hostsMu.Unlock()
+	}
+
+	pages := l.pages.Add(1)
+	if l.cfg.MaxPages > 0 {
+		if pages > l.cfg.MaxPages {
+			// Страница не загружается: снимаем резерв, чтобы он не занимал бюджет хоста
+			// и не попал в итоги обхода.
+			l.pages.Add(-1)
+			l.releaseHost(host)
+			return false, errLimitReached
+		}
+		if pages == l.cfg.MaxPages {
+			// Эта страница последняя: загружаем ее и больше задач не раздаем.
+			l.stop(StopMaxPages)
+		}
+	}
+	return true, nil
+}
+
+// releaseHost возвращает хосту страницу, зарезервированную в reservePage.
+func (l *limiter) releaseHost(host string) {
+	if l.cfg.MaxPagesPerHost <= 0 {
+		return
+	}
+	l.hostsMu.Lock()
+	l.hosts[host]--
+	l.hostsMu.Unlock()
+}
+
+// hostExhausted сообщает, что бюджет хоста исчерпан и ставить его ссылки в очередь незачем.
+func (l *limiter) hostExhausted(host string) bool {
+	if l.cfg.MaxPagesPerHost <= 0 {
+		return false
+	}
+	l.hostsMu.Lock()
+	defer l.hostsMu.Unlock()
+	return l.hosts[host] >= l.cfg.MaxPagesPerHost
+}
+
+func (l *limiter) addBytes(n int) {
+	total := l.bytes.Add(int64(n))
+	if l.cfg.MaxBytes > 0 && total >= l.cfg.MaxBytes {
+		l.stop(StopMaxBytes)
+	}
+}
+
+func (l *limiter) stop(reason string) {
+	l.stopOnce.Do(func() {
+		l.reason = reason
+		close(l.stopped)
+	})
+}
+
+// done закрывается, когда обход нужно остановить.
+func (l *limiter) done() <-chan struct{} {
+	return l.stopped
+}
+
+func (l *limiter) isStopped() bool {
+	select {
+	case <-l.stopped:
+		return true
+	default:
+		return false
+	}
+}
+
+// begin отмечает начало обхода, от которого считается Result.Duration.
+func (l *limiter) begin() {
+	l.started.Store(time.Now().UnixNano())
+}
+
+// finish фиксирует Result.Duration, чтобы после окончания обхода оно не росло.
+func (l *limiter) finish() {
+	l.finished.Store(time.Now().UnixNano())
+}
+
+func (l *limiter) result() Result {
+	r := Result{Pages: l.pages.Load(), Bytes: l.bytes.Load()}
+	started, finished := l.started.Load(), l.finished.Load()
+	switch {
+	case started == 0:
+	case finished != 0:
+		r.Duration = time.Duration(finished - started)
+	default:
+		r.Duration = time.Since(time.Unix(0, started))
+	}
+	if l.isStopped() {
+		r.StopReason = l.reason
+	}
+	return r
+}
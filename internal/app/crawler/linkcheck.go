@@ -0,0 +1,91 @@
+package crawler
+
+import (
+	"context"
+	"log/slog"
+
+	"justycrawler/internal/domain"
+)
+
+// LinkCheck — режим проверки ссылок. Каждая ссылка обойденной страницы, вид
+// которой входит в FollowLinks, проверяется Checker, а битые передаются в Report
+// вместе со страницей, на которой найдены. Ссылки вне области обхода только
+// проверяются, по ним обход не идет. Пустой Checker — режим выключен.
+type LinkCheck struct {
+	Checker LinkChecker
+	Report  LinkReport
+}
+
+// linkCheckResult — итог проверки URL. Пока проверка идет, done открыт.
+type linkCheckResult struct {
+	done    chan struct{}
+	failure *domain.Failure // nil — ссылка рабочая.
+}
+
+// checkLinks проверяет ссылки страницы и сообщает о битых.
+func (c *Crawler) checkLinks(ctx context.Context, task domain.Task, links []domain.Link, log *slog.Logger) {
+	for _, link := range links {
+		if !c.follow[link.Kind] {
+			continue
+		}
+		failure := c.checkLink(ctx, link.URL)
+		if failure == nil {
+			continue
+		}
+
+		broken := domain.BrokenLink{
+			Source:        task.URL,
+			URL:           link.URL,
+			Kind:          link.Kind,
+			Anchor:        link.Text,
+			StatusCode:    failure.StatusCode,
+			RedirectChain: failure.RedirectChain,
+			ErrorClass:    failure.Class,
+			Error:         failure.Message,
+		}
+		log.DebugContext(ctx, "Битая ссылка",
+			slog.String("link", link.URL), slog.String("class", string(failure.Class)))
+		if err := c.opts.LinkCheck.Report.Broken(ctx, broken); err != nil {
+			log.ErrorContext(ctx, "Не удалось записать битую ссылку в отчет",
+				slog.String("link", link.URL), slog.Any("error", err))
+		}
+	}
+}
+
+// checkLink проверяет URL один раз за обход: одна и та же ссылка обычно стоит
+// на многих страницах. Параллельные проверки того же URL ждут первую.
+func (c *Crawler) checkLink(ctx context.Context, rawURL string) *domain.Failure {
+	entry := &linkCheckResult{done: make(chan struct{})}
+	if existing, loaded := c.checked.LoadOrStore(rawURL, entry); loaded {
+		entry, _ = existing.(*linkCheckResult)
+		select {
+		case <-entry.done:
+			return entry.failure
+		case <-ctx.Done():
+			return nil
+		}
+	}
+	defer close(entry.done)
+
+	entry.failure = c.runCheck(ctx, rawURL)
+	return entry.failure
+}
+
+// runCheck проверяет URL, соблюдая ограничения нагрузки на его хост.
+func (c *Crawler) runCheck(ctx context.Context, rawURL string) *domain.Failure {
+	pace, err := c.pace(ctx, rawURL)
+	if err != nil {
+		failure := classifyFailure(domain.Task{}, err)
+		return &failure
+	}
+
+	if err := c.opts.LinkCheck.Checker.Check(ctx, rawURL, pace); err != nil {
+		if ctx.Err() != nil {
+			// Обход прерван — это не ошибка ссылки.
+			return nil
+		}
+		failure := classifyFailure(domain.Task{}, err)
+		return &failure
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package crawler
+
+import (
+	"fmt"
+	"regexp"
+
+	"justycrawler/internal/domain"
+)
+
+// Цели правил оценки.
+const (
+	ScoreTargetURL    = "url"
+	ScoreTargetAnchor = "anchor"
+)
+
+// defaultSitemapPriority — приоритет URL в sitemap, если он не указан (по протоколу sitemaps.org).
+const defaultSitemapPriority = 0.5
+
+// ScoreFunc оценивает задачу для порядка domain.OrderPriority: чем больше оценка,
+// тем раньше задача будет обойдена. Задача содержит URL, глубину, текст ссылки
+// и атрибуты из sitemap.
+type ScoreFunc func(task domain.Task) float64
+
+// DefaultScore предпочитает неглубокие страницы, а на одной глубине — страницы
+// с большим приоритетом в sitemap.
+func DefaultScore(task domain.Task) float64 {
+	priority := defaultSitemapPriority
+	if task.Sitemap != nil && task.Sitemap.Priority > 0 {
+		priority = task.Sitemap.Priority
+	}
+	return priority - float64(task.Depth)
+}
+
+// ScoreRule добавляет Boost к оценке задачи, если регулярное выражение Pattern
+// совпадает с URL или текстом ссылки (Target).
+type ScoreRule struct {
+	Target  string
+	Pattern string
+	Boost   float64
+}
+
+// NewRuleScore строит оценку «DefaultScore плюс прибавки совпавших правил».
+func NewRuleScore(rules []ScoreRule) (ScoreFunc, error) {
+	type compiledRule struct {
+		target string
+		re     *regexp.Regexp
+		boost  float64
+	}
+
+	compiled := make([]compiledRule, 0, len(rules))
+	for i, rule := range rules {
+		switch rule.Target {
+		case ScoreTargetURL, ScoreTargetAnchor:
+		case "":
+			rule.Target = ScoreTargetURL
+		default:
+			return nil, fmt.Errorf("правило оценки #%d: неизвестная цель %q, допустимо: url, anchor", i+1, rule.Target)
+		}
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("правило оценки #%d: %w", i+1, err)
+		}
+		compiled = append(compiled, compiledRule{target: rule.Target, re: re, boost: rule.Boost})
+	}
+
+	return func(task domain.Task) float64 {
+		score := DefaultScore(task)
+		for _, rule := range compiled {
+			subject := task.URL
+			if rule.target == ScoreTargetAnchor {
+				subject = task.AnchorText
+			}
+			if rule.re.MatchString(subject) {
+				score += rule.boost
+			}
+		}
+		return score
+	}, nil
+}
@@ -0,0 +1,129 @@
+package crawler
+
+import (
+	"container/heap"
+
+	"justycrawler/internal/domain"
+)
+
+// taskQueue — очередь задач MemoryFrontier. Реализация определяет порядок обхода.
+type taskQueue interface {
+	push(task domain.Task)
+	pop() (domain.Task, bool)
+	len() int
+}
+
+func newTaskQueue(order domain.Ordering) taskQueue {
+	switch order {
+	case domain.OrderDFS:
+		return &lifoQueue{}
+	case domain.OrderPriority:
+		return &priorityQueue{}
+	default:
+		return &fifoQueue{}
+	}
+}
+
+// fifoQueue выдает задачи в порядке добавления. Ссылки страницы добавляются после
+// всех задач меньшей глубины, поэтому обход идет строго в ширину.
+type fifoQueue struct {
+	tasks []domain.Task
+}
+
+func (q *fifoQueue) push(task domain.Task) {
+	q.tasks = append(q.tasks, task)
+}
+
+func (q *fifoQueue) pop() (domain.Task, bool) {
+	if len(q.tasks) == 0 {
+		return domain.Task{}, false
+	}
+	task := q.tasks[0]
+	q.tasks[0] = domain.Task{}
+	q.tasks = q.tasks[1:]
+	return task, true
+}
+
+func (q *fifoQueue) len() int {
+	return len(q.tasks)
+}
+
+// lifoQueue выдает последнюю добавленную задачу — обход в глубину.
+type lifoQueue struct {
+	tasks []domain.Task
+}
+
+func (q *lifoQueue) push(task domain.Task) {
+	q.tasks = append(q.tasks, task)
+}
+
+func (q *lifoQueue) pop() (domain.Task, bool) {
+	if len(q.tasks) == 0 {
+		return domain.Task{}, false
+	}
+	last := len(q.tasks) - 1
+	task := q.tasks[last]
+	q.tasks[last] = domain.Task{}
+	q.tasks = q.tasks[:last]
+	return task, true
+}
+
+func (q *lifoQueue) len() int {
+	return len(q.tasks)
+}
+
+// priorityQueue выдает задачу с наибольшим Priority, при равенстве — добавленную раньше.
+type priorityQueue struct {
+	items taskHeap
+	seq   uint64
+}
+
+func (q *priorityQueue) push(task domain.Task) {
+	q.seq++
+	heap.Push(&q.items, heapItem{task: task, seq: q.seq})
+}
+
+func (q *priorityQueue) pop() (domain.Task, bool) {
+	if len(q.items) == 0 {
+		return domain.Task{}, false
+	}
+	item, _ := heap.Pop(&q.items).(heapItem)
+	return item.task, true
+}
+
+func (q *priorityQueue) len() int {
+	return len(q.items)
+}
+
+type heapItem struct {
+	task domain.Task
+	seq  uint64
+}
+
+// taskHeap реализует heap.Interface для priorityQueue.
+type taskHeap []heapItem
+
+func (h taskHeap) Len() int { return len(h) }
+
+func (h taskHeap) Less(i, j int) bool {
+	if h[i].task.Priority != h[j].task.Priority {
+		return h[i].task.Priority > h[j].task.Priority
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h taskHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *taskHeap) Push(x any) {
+	item, _ := x.(heapItem)
+	*h = append(*h, item)
+}
+
+func (h *taskHeap) Pop() any {
+	old := *h
+	last := len(old) - 1
+	item := old[last]
+	old[last] = heapItem{}
+	*h = old[:last]
+	return item
+}
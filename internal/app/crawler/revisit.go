@@ -0,0 +1,92 @@
+package crawler
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"justycrawler/internal/domain"
+)
+
+// RevisitFunc возвращает, через сколько после посещения URL задачи снова становится
+// доступен для обхода; 0 — URL обходится один раз.
+type RevisitFunc func(task domain.Task) time.Duration
+
+// RevisitRule задает интервал повторного обхода URL, с которыми совпадает
+// регулярное выражение Pattern.
+type RevisitRule struct {
+	Pattern  string
+	Interval time.Duration
+}
+
+// Revisit — политика повторного обхода.
+type Revisit struct {
+	Interval time.Duration // Интервал по умолчанию; 0 — URL обходится один раз.
+	Rules    []RevisitRule // Решает первое совпавшее правило.
+	// SitemapChangeFreq берет интервал из changefreq для URL из sitemap,
+	// если не совпало ни одно правило.
+	SitemapChangeFreq bool
+}
+
+// changeFreqIntervals — интервалы для значений changefreq протокола sitemaps.org.
+// always означает «при каждом обходе», never — архивные страницы, которые не меняются.
+func changeFreqIntervals() map[string]time.Duration {
+	const day = 24 * time.Hour
+	return map[string]time.Duration{
+		"always":  time.Nanosecond,
+		"hourly":  time.Hour,
+		"daily":   day,
+		"weekly":  7 * day,
+		"monthly": 30 * day,
+		"yearly":  365 * day,
+		"never":   0,
+	}
+}
+
+// NewRevisit строит RevisitFunc по политике: правило, затем changefreq из sitemap,
+// затем интервал по умолчанию.
+func NewRevisit(policy Revisit) (RevisitFunc, error) {
+	type compiledRule struct {
+		re       *regexp.Regexp
+		interval time.Duration
+	}
+
+	compiled := make([]compiledRule, 0, len(policy.Rules))
+	for i, rule := range policy.Rules {
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("правило повторного обхода #%d: %w", i+1, err)
+		}
+		compiled = append(compiled, compiledRule{re: re, interval: rule.Interval})
+	}
+	changeFreq := changeFreqIntervals()
+
+	return func(task domain.Task) time.Duration {
+		for _, rule := range compiled {
+			if rule.re.MatchString(task.URL) {
+				return rule.interval
+			}
+		}
+		if policy.SitemapChangeFreq && task.Sitemap != nil {
+			if interval, ok := changeFreq[task.Sitemap.ChangeFreq]; ok {
+				return interval
+			}
+		}
+		return policy.Interval
+	}, nil
+}
+
+// staleBefore возвращает границу свежести URL задачи для State.Add: URL, посещенный
+// раньше нее, обходится снова. Нулевое время — URL обходится один раз. Граница
+// считается от текущего момента, поэтому долгий обход (например, задание сервера)
+// возвращается к URL, как только истекает его интервал.
+func (c *Crawler) staleBefore(task domain.Task) time.Time {
+	if c.opts.Revisit == nil {
+		return time.Time{}
+	}
+	interval := c.opts.Revisit(task)
+	if interval <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(-interval)
+}
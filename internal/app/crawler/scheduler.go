@@ -0,0 +1,120 @@
+package crawler
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Politeness — ограничения нагрузки на хосты.
+type Politeness struct {
+	Delay          time.Duration // Минимальная пауза между запросами к одному хосту.
+	MaxConcurrency int           // Максимум одновременных запросов к одному хосту.
+	Hosts          map[string]HostLimits
+}
+
+// HostLimits переопределяет глобальные ограничения для конкретного хоста.
+// Нулевые значения означают «взять глобальное».
+type HostLimits struct {
+	Delay          time.Duration
+	MaxConcurrency int
+}
+
+// hostScheduler следит, чтобы воркеры не обращались к одному хосту
+// чаще разрешенного и не держали к нему больше MaxConcurrency запросов.
+type hostScheduler struct {
+	politeness Politeness
+
+	mu    sync.Mutex
+	slots map[string]*hostSlot
+}
+
+type hostSlot struct {
+	inFlight chan struct{}
+
+	mu          sync.Mutex
+	nextAllowed time.Time
+}
+
+func newHostScheduler(politeness Politeness) *hostScheduler {
+	return &hostScheduler{
+		politeness: politeness,
+		slots:      make(map[string]*hostSlot),
+	}
+}
+
+// acquire блокируется, пока к хосту нельзя будет отправить запрос, и возвращает функцию,
+// которую нужно вызвать после завершения запроса. minDelay позволяет увеличить паузу,
+// например до Crawl-delay из robots.txt.
+func (s *hostScheduler) acquire(ctx context.Context, host string, minDelay time.Duration) (func(), error) {
+	slot := s.slot(host)
+
+	select {
+	case slot.inFlight <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	release := func() { <-slot.inFlight }
+
+	delay := max(s.limitsFor(host).Delay, minDelay)
+
+	// Резервируем ближайшее свободное время под мьютексом, а ждем уже без него,
+	// чтобы остальные воркеры могли встать в очередь за следующими слотами.
+	slot.mu.Lock()
+	start := time.Now()
+	if slot.nextAllowed.After(start) {
+		start = slot.nextAllowed
+	}
+	slot.nextAllowed = start.Add(delay)
+	slot.mu.Unlock()
+
+	wait := time.Until(start)
+	if wait <= 0 {
+		return release, nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return release, nil
+	case <-ctx.Done():
+		release()
+		return nil, ctx.Err()
+	}
+}
+
+// slot возвращает очередь хоста. Регистр имени хоста не важен, как и в limitsFor.
+func (s *hostScheduler) slot(host string) *hostSlot {
+	host = strings.ToLower(host)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	slot, ok := s.slots[host]
+	if !ok {
+		slot = &hostSlot{inFlight: make(chan struct{}, s.limitsFor(host).MaxConcurrency)}
+		s.slots[host] = slot
+	}
+	return slot
+}
+
+func (s *hostScheduler) limitsFor(host string) HostLimits {
+	limits := HostLimits{
+		Delay:          s.politeness.Delay,
+		MaxConcurrency: s.politeness.MaxConcurrency,
+	}
+
+	if override, ok := s.politeness.Hosts[strings.ToLower(host)]; ok {
+		if override.Delay > 0 {
+			limits.Delay = override.Delay
+		}
+		if override.MaxConcurrency > 0 {
+			limits.MaxConcurrency = override.MaxConcurrency
+		}
+	}
+
+	limits.MaxConcurrency = max(limits.MaxConcurrency, 1)
+	return limits
+}
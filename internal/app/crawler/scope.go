@@ -0,0 +1,191 @@
+package crawler
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// Режимы области обхода.
+const (
+	ScopeHost       = "host"        // Только хост стартового URL.
+	ScopeDomain     = "domain"      // Весь регистрируемый домен: www.example.com и blog.example.com.
+	ScopeSubdomains = "subdomains"  // Хост стартового URL и его поддомены.
+	ScopePathPrefix = "path-prefix" // Хост стартового URL и путь внутри его «каталога».
+	ScopeAny        = "any"         // Без ограничений.
+)
+
+// Scope — какие ссылки считаются частью обхода.
+type Scope struct {
+	Mode string
+	// ExtraHosts — хосты, которые обходятся всегда, независимо от режима.
+	// «*.example.com» разрешает все поддомены example.com.
+	ExtraHosts []string
+}
+
+// scope — Scope, привязанный к конкретному стартовому URL.
+type scope struct {
+	mode       string
+	host       string // Хост с портом, для режима host.
+	hostname   string // Хост без порта в нижнем регистре.
+	domain     string // Регистрируемый домен по списку публичных суффиксов.
+	pathPrefix string
+
+	extraHosts    map[string]struct{}
+	extraSuffixes []string
+}
+
+func newScope(cfg Scope, start *url.URL) (*scope, error) {
+	s := &scope{
+		mode:       cfg.Mode,
+		host:       strings.ToLower(start.Host),
+		hostname:   strings.ToLower(start.Hostname()),
+		pathPrefix: dirPrefix(start.Path),
+		extraHosts: make(map[string]struct{}, len(cfg.ExtraHosts)),
+	}
+
+	switch s.mode {
+	case ScopeHost, ScopeSubdomains, ScopePathPrefix, ScopeAny:
+	case "":
+		s.mode = ScopeHost
+	case ScopeDomain:
+		s.domain = registrableDomain(s.hostname)
+	default:
+		return nil, fmt.Errorf("неизвестный режим scope %q, допустимо: host, domain, subdomains, path-prefix, any", s.mode)
+	}
+
+	for _, host := range cfg.ExtraHosts {
+		host = strings.ToLower(strings.TrimSpace(host))
+		if host == "" {
+			continue
+		}
+		// Суффикс хранится с точкой: «*.example.com» не должен разрешать evilexample.com.
+		if suffix, ok := strings.CutPrefix(host, "*"); ok && strings.HasPrefix(suffix, ".") {
+			host = suffix
+		}
+		if host == "." || strings.Contains(host, "*") {
+			return nil, fmt.Errorf("невалидный хост в scope_hosts %q: маска допустима только в виде *.example.com", host)
+		}
+		if strings.HasPrefix(host, ".") {
+			s.extraSuffixes = append(s.extraSuffixes, host)
+			continue
+		}
+		s.extraHosts[host] = struct{}{}
+	}
+
+	return s, nil
+}
+
+// contains сообщает, входит ли ссылка в область обхода.
+func (s *scope) contains(link *url.URL) bool {
+	hostname := strings.ToLower(link.Hostname())
+	if s.isExtraHost(hostname) {
+		return true
+	}
+
+	switch s.mode {
+	case ScopeAny:
+		return true
+	case ScopeDomain:
+		return registrableDomain(hostname) == s.domain
+	case ScopeSubdomains:
+		return hostname == s.hostname || strings.HasSuffix(hostname, "."+s.hostname)
+	case ScopePathPrefix:
+		return strings.ToLower(link.Host) == s.host && strings.HasPrefix(link.Path, s.pathPrefix)
+	default:
+		return strings.ToLower(link.Host) == s.host
+	}
+}
+
+func (s *scope) isExtraHost(hostname string) bool {
+	if _, ok := s.extraHosts[hostname]; ok {
+		return true
+	}
+	for _, suffix := range s.extraSuffixes {
+		if strings.HasSuffix(hostname, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// seedScopes — области обхода стартовых URL: у каждого сида своя. Задачи помнят
+// свой сид, поэтому область восстанавливается и для задач, поставленных другим
+// экземпляром с другим набором сидов.
+type seedScopes struct {
+	cfg Scope
+	// fallback — сид для задач без поля Seed, поставленных до появления нескольких сидов.
+	fallback string
+
+	mu     sync.RWMutex
+	bySeed map[string]*scope
+}
+
+func newSeedScopes(cfg Scope, seeds []string) (*seedScopes, error) {
+	s := &seedScopes{cfg: cfg, bySeed: make(map[string]*scope, len(seeds))}
+	for _, seed := range seeds {
+		if _, err := s.add(seed); err != nil {
+			return nil, err
+		}
+	}
+	if len(seeds) > 0 {
+		s.fallback = seeds[0]
+	}
+	return s, nil
+}
+
+func (s *seedScopes) add(seed string) (*scope, error) {
+	start, err := url.Parse(seed)
+	if err != nil {
+		return nil, fmt.Errorf("невалидный стартовый URL %s: %w", seed, err)
+	}
+	sc, err := newScope(s.cfg, start)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.bySeed[seed] = sc
+	s.mu.Unlock()
+	return sc, nil
+}
+
+// contains сообщает, входит ли ссылка в область обхода сида.
+func (s *seedScopes) contains(seed string, link *url.URL) bool {
+	if seed == "" {
+		seed = s.fallback
+	}
+
+	s.mu.RLock()
+	sc, ok := s.bySeed[seed]
+	s.mu.RUnlock()
+	if !ok {
+		var err error
+		if sc, err = s.add(seed); err != nil {
+			return false
+		}
+	}
+	return sc.contains(link)
+}
+
+// registrableDomain возвращает домен, который можно зарегистрировать (example.co.uk
+// для a.b.example.co.uk). Для IP-адресов и localhost возвращает хост как есть.
+func registrableDomain(hostname string) string {
+	domain, err := publicsuffix.EffectiveTLDPlusOne(hostname)
+	if err != nil {
+		return hostname
+	}
+	return domain
+}
+
+// dirPrefix возвращает «каталог» пути: /docs/intro -> /docs/, /docs/ -> /docs/.
+func dirPrefix(p string) string {
+	idx := strings.LastIndex(p, "/")
+	if idx < 0 {
+		return "/"
+	}
+	return p[:idx+1]
+}
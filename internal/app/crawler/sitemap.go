@@ -0,0 +1,85 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+
+	"justycrawler/internal/domain"
+)
+
+// seedSitemaps ставит в очередь URL из sitemap сайтов стартовых URL. Они проходят те же
+// проверки области обхода и фильтров, что и ссылки со страниц, и обходятся с глубины 0.
+func (c *Crawler) seedSitemaps(ctx context.Context, seeds []string) error {
+	found := 0
+	sites := make(map[string]struct{}, len(seeds))
+	for _, seed := range seeds {
+		// Sitemap ищутся по корню сайта, поэтому для сидов одного хоста хватит одного поиска.
+		parsedSeed, err := url.Parse(seed)
+		if err != nil {
+			continue
+		}
+		site := parsedSeed.Scheme + "://" + parsedSeed.Host
+		if _, ok := sites[site]; ok {
+			continue
+		}
+		sites[site] = struct{}{}
+
+		n, err := c.seedSitemap(ctx, seed)
+		if err != nil {
+			return err
+		}
+		found += n
+	}
+
+	if found == 0 && c.opts.SitemapOnly {
+		return errors.New("в sitemap не найдено ни одного URL")
+	}
+	return nil
+}
+
+// seedSitemap ставит в очередь URL из sitemap сайта одного сида и возвращает,
+// сколько URL нашлось.
+func (c *Crawler) seedSitemap(ctx context.Context, seed string) (int, error) {
+	log := c.logger.With(slog.String("seed", seed))
+
+	entries, err := c.sitemaps.Discover(ctx, seed)
+	if err != nil {
+		log.WarnContext(ctx, "Часть sitemap загрузить не удалось", slog.Any("error", err))
+	}
+
+	queued := 0
+	for _, entry := range entries {
+		link, normErr := c.norm.Normalize(entry.URL)
+		if normErr != nil {
+			continue
+		}
+		parsedLink, parseErr := url.Parse(link)
+		if parseErr != nil || !c.scopes.contains(seed, parsedLink) {
+			continue
+		}
+
+		meta := entry.Meta
+		task := domain.Task{URL: link, Depth: 0, ParentURL: meta.Source, Seed: seed, Sitemap: &meta}
+		if !c.passesFilter(ctx, task, parsedLink, log) {
+			continue
+		}
+
+		added, addErr := c.state.Add(ctx, link, c.staleBefore(task))
+		if addErr != nil {
+			return 0, fmt.Errorf("не удалось добавить URL из sitemap в стейт: %w", addErr)
+		}
+		if !added {
+			continue
+		}
+		if pushErr := c.push(ctx, task); pushErr != nil {
+			return 0, fmt.Errorf("не удалось добавить URL из sitemap во фронтир: %w", pushErr)
+		}
+		queued++
+	}
+
+	log.InfoContext(ctx, "Добавлены URL из sitemap.", slog.Int("found", len(entries)), slog.Int("queued", queued))
+	return len(entries), nil
+}
@@ -1,7 +1,11 @@
 package config
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"os"
 	"strings"
 	"time"
 
@@ -12,28 +16,119 @@ import (
 const (
 	DefaultWorkerCount = 10
 	DefaultMaxDepth    = 2
+	DefaultMaxHTMLSize = 1 << 20 // 1 МиБ
+	DefaultMaxTextSize = 1 << 20 // 1 МиБ
+	DefaultJobTTL      = 24 * time.Hour
+
+	DefaultMaxURLLength = 2048
+
+	// jobIDBytes — длина сгенерированного идентификатора обхода в байтах.
+	jobIDBytes = 8
+)
+
+const (
+	ScopeHost = "host"
+	ScopeAny  = "any"
+)
+
+const (
+	FrontierMemory = "memory"
+	FrontierRedis  = "redis"
+)
+
+const (
+	ModeCrawl      = "crawl"
+	ModeCheckLinks = "check-links"
+	ModeServer     = "server"
+)
+
+const (
+	OrderBFS      = "bfs"
+	OrderDFS      = "dfs"
+	OrderPriority = "priority"
 )
 
 type Config struct {
-	StartURL     string `mapstructure:"start_url"`
-	SameHost     bool   `mapstructure:"same_host"`
-	MaxDepth     int    `mapstructure:"max_depth"`
-	WorkerCount  int    `mapstructure:"worker_count"`
-	ForceRecrawl bool   `mapstructure:"force_recrawl"`
-	HTTP         HTTP   `mapstructure:"http"`
-	Mongo        Mongo  `mapstructure:"mongo"`
-	Redis        Redis  `mapstructure:"redis"`
-	Log          Log    `mapstructure:"log"`
+	Mode           string     `mapstructure:"mode"`      // crawl, check-links или server.
+	StartURLs      []string   `mapstructure:"start_url"` // Строка или список; флаг можно повторять.
+	SeedFile       string     `mapstructure:"seed_file"` // Файл со стартовыми URL, по одному на строку.
+	SameHost       bool       `mapstructure:"same_host"` // Устарело: используйте scope.
+	Scope          string     `mapstructure:"scope"`
+	ScopeHosts     []string   `mapstructure:"scope_hosts"`
+	MaxDepth       int        `mapstructure:"max_depth"`
+	WorkerCount    int        `mapstructure:"worker_count"`
+	Limits         Limits     `mapstructure:"limits"`
+	Revisit        Revisit    `mapstructure:"revisit"`
+	ForceRecrawl   bool       `mapstructure:"force_recrawl"`
+	Resume         bool       `mapstructure:"resume"`
+	RetryFailed    bool       `mapstructure:"retry_failed"` // Повторить URL, завершившиеся ошибкой.
+	Incremental    bool       `mapstructure:"incremental"`  // Повторный обход с условными запросами.
+	Distributed    bool       `mapstructure:"distributed"`
+	WorkerID       string     `mapstructure:"worker_id"`
+	JobID          string     `mapstructure:"job_id"`          // Отметка результатов и запуска; по умолчанию генерируется.
+	SharedKeys     bool       `mapstructure:"-"`               // job_id не задан: стейт и фронтир в общих ключах.
+	DedupCanonical bool       `mapstructure:"dedup_canonical"` // Canonical уже встречался — страница дубликат.
+	HTTP           HTTP       `mapstructure:"http"`
+	Mongo          Mongo      `mapstructure:"mongo"`
+	Redis          Redis      `mapstructure:"redis"`
+	Frontier       Frontier   `mapstructure:"frontier"`
+	Robots         Robots     `mapstructure:"robots"`
+	Sitemap        Sitemap    `mapstructure:"sitemap"`
+	Politeness     Politeness `mapstructure:"politeness"`
+	Content        Content    `mapstructure:"content"`
+	Filter         Filter     `mapstructure:"filter"`
+	Links          Links      `mapstructure:"links"`
+	Normalize      Normalize  `mapstructure:"normalize"`
+	CheckLinks     CheckLinks `mapstructure:"check_links"`
+	Metrics        Metrics    `mapstructure:"metrics"`
+	Server         Server     `mapstructure:"server"`
+	Log            Log        `mapstructure:"log"`
+
+	settings map[string]any // Исходные настройки без секретов, см. Snapshot.
+}
+
+// Limits — когда останавливать обход; 0 — без ограничения.
+type Limits struct {
+	MaxPages        int64         `mapstructure:"max_pages"`
+	MaxDuration     time.Duration `mapstructure:"max_duration"`
+	MaxPagesPerHost int64         `mapstructure:"max_pages_per_host"`
+	MaxBytes        int64         `mapstructure:"max_bytes"`
+}
+
+// Revisit — когда URL снова становится доступен для обхода.
+type Revisit struct {
+	Interval          time.Duration `mapstructure:"interval"` // 0 — URL обходится один раз.
+	SitemapChangeFreq bool          `mapstructure:"sitemap_changefreq"`
+	Rules             []RevisitRule `mapstructure:"rules"`
+}
+
+// Enabled сообщает, что URL могут обходиться повторно.
+func (r Revisit) Enabled() bool {
+	return r.Interval > 0 || len(r.Rules) > 0 || r.SitemapChangeFreq
+}
+
+// RevisitRule задает интервал для URL, совпавших с регулярным выражением Pattern.
+type RevisitRule struct {
+	Pattern  string        `mapstructure:"pattern"`
+	Interval time.Duration `mapstructure:"interval"`
 }
 
 type HTTP struct {
 	Timeout time.Duration `mapstructure:"timeout"`
+	Retry   Retry         `mapstructure:"retry"`
+}
+
+type Retry struct {
+	MaxAttempts int           `mapstructure:"max_attempts"`
+	BaseDelay   time.Duration `mapstructure:"base_delay"`
+	MaxDelay    time.Duration `mapstructure:"max_delay"`
 }
 
 type Mongo struct {
-	URI        string `mapstructure:"uri"`
-	Database   string `mapstructure:"database"`
-	Collection string `mapstructure:"collection"`
+	URI            string `mapstructure:"uri"`
+	Database       string `mapstructure:"database"`
+	Collection     string `mapstructure:"collection"`
+	RunsCollection string `mapstructure:"runs_collection"` // Записи о запусках обхода.
 }
 
 type Redis struct {
@@ -43,6 +138,101 @@ type Redis struct {
 	SetKey   string `mapstructure:"set_key"`
 }
 
+type Frontier struct {
+	Backend           string        `mapstructure:"backend"` // memory или redis
+	KeyPrefix         string        `mapstructure:"key_prefix"`
+	VisibilityTimeout time.Duration `mapstructure:"visibility_timeout"`
+	Order             string        `mapstructure:"order"` // bfs, dfs или priority
+	// ScoreRules — прибавки к оценке задач для порядка priority.
+	ScoreRules []ScoreRule `mapstructure:"score_rules"`
+}
+
+// ScoreRule прибавляет Boost к оценке URL, если Pattern (регулярное выражение)
+// совпадает с URL или текстом ссылки.
+type ScoreRule struct {
+	Target  string  `mapstructure:"target"` // url или anchor (по умолчанию url)
+	Pattern string  `mapstructure:"pattern"`
+	Boost   float64 `mapstructure:"boost"`
+}
+
+type Robots struct {
+	Enabled   bool          `mapstructure:"enabled"`
+	UserAgent string        `mapstructure:"user_agent"`
+	CacheTTL  time.Duration `mapstructure:"cache_ttl"`
+	// IgnoreDirectives отключает rel="nofollow", meta robots и X-Robots-Tag.
+	IgnoreDirectives bool `mapstructure:"ignore_directives"`
+}
+
+// Sitemap — засев обхода URL из sitemap (строки Sitemap в robots.txt или /sitemap.xml).
+type Sitemap struct {
+	Enabled bool `mapstructure:"enabled"`
+	Only    bool `mapstructure:"only"` // Обходить только URL из sitemap, не переходя по ссылкам.
+}
+
+type Politeness struct {
+	Delay          time.Duration    `mapstructure:"delay"`
+	MaxConcurrency int              `mapstructure:"max_concurrency"`
+	Hosts          []HostPoliteness `mapstructure:"hosts"`
+}
+
+// HostPoliteness переопределяет ограничения нагрузки для одного хоста.
+// Хосты задаются списком, а не словарем, потому что viper считает точки в ключах вложенностью.
+type HostPoliteness struct {
+	Host           string        `mapstructure:"host"`
+	Delay          time.Duration `mapstructure:"delay"`
+	MaxConcurrency int           `mapstructure:"max_concurrency"`
+}
+
+type Content struct {
+	StoreHTML       bool `mapstructure:"store_html"`
+	MaxHTMLSize     int  `mapstructure:"max_html_size"`
+	ExtractMetadata bool `mapstructure:"extract_metadata"`
+	MaxTextSize     int  `mapstructure:"max_text_size"`
+}
+
+type Filter struct {
+	Rules          []FilterRule `mapstructure:"rules"`
+	SkipExtensions []string     `mapstructure:"skip_extensions"`
+	MaxURLLength   int          `mapstructure:"max_url_length"`
+}
+
+// FilterRule — правило include/exclude. Правила применяются по порядку, решает первое совпавшее.
+type FilterRule struct {
+	Name    string `mapstructure:"name"`
+	Action  string `mapstructure:"action"`  // include или exclude
+	Target  string `mapstructure:"target"`  // url, host, path или query
+	Pattern string `mapstructure:"pattern"` // регулярное выражение или glob
+	Syntax  string `mapstructure:"syntax"`  // regex или glob (по умолчанию glob)
+}
+
+// Links — какие виды ссылок обходить: navigation, form, resource, embed, redirect.
+// Ссылки остальных видов только записываются в результаты.
+type Links struct {
+	Follow []string `mapstructure:"follow"`
+}
+
+// CheckLinks — отчет режима check-links.
+type CheckLinks struct {
+	Format string `mapstructure:"format"` // console, json или csv.
+	Output string `mapstructure:"output"` // Файл отчета; пусто — stdout.
+}
+
+// Metrics — HTTP-сервер метрик Prometheus.
+type Metrics struct {
+	Addr string `mapstructure:"addr"` // Адрес, например ":9090"; пусто — сервер выключен.
+}
+
+// Server — HTTP API режима server.
+type Server struct {
+	Addr string `mapstructure:"addr"`
+	// JobTTL — сколько завершенное задание хранится в памяти сервера; 0 — до остановки.
+	JobTTL time.Duration `mapstructure:"job_ttl"`
+}
+
+type Normalize struct {
+	StripParams []string `mapstructure:"strip_params"`
+}
+
 type Log struct {
 	Level string `mapstructure:"level"`
 }
@@ -50,28 +240,118 @@ type Log struct {
 // Порядок приоритетов: флаги > переменные окружения > файл config.yaml
 func New() (*Config, error) {
 	viper.SetDefault("http.timeout", "30s")
+	viper.SetDefault("http.retry.max_attempts", 3)
+	viper.SetDefault("http.retry.base_delay", "500ms")
+	viper.SetDefault("http.retry.max_delay", "30s")
 	viper.SetDefault("mongo.uri", "mongodb://localhost:27017")
 	viper.SetDefault("mongo.database", "crawler_db")
 	viper.SetDefault("mongo.collection", "links")
+	viper.SetDefault("mongo.runs_collection", "runs")
 
 	viper.SetDefault("redis.addr", "localhost:6379")
 	viper.SetDefault("redis.password", "")
 	viper.SetDefault("redis.db", 0)
 	viper.SetDefault("redis.set_key", "crawler:visited_urls")
 
+	viper.SetDefault("frontier.backend", FrontierRedis)
+	viper.SetDefault("frontier.key_prefix", "crawler:frontier")
+	viper.SetDefault("frontier.visibility_timeout", "5m")
+	viper.SetDefault("frontier.order", OrderBFS)
+
+	viper.SetDefault("robots.enabled", true)
+	viper.SetDefault("robots.user_agent", "justycrawler")
+	viper.SetDefault("robots.cache_ttl", "24h")
+	viper.SetDefault("robots.ignore_directives", false)
+
+	viper.SetDefault("sitemap.enabled", false)
+	viper.SetDefault("sitemap.only", false)
+
+	viper.SetDefault("politeness.delay", "500ms")
+	viper.SetDefault("politeness.max_concurrency", 2)
+
+	viper.SetDefault("content.store_html", false)
+	viper.SetDefault("content.max_html_size", DefaultMaxHTMLSize)
+	viper.SetDefault("content.extract_metadata", false)
+	viper.SetDefault("content.max_text_size", DefaultMaxTextSize)
+
+	viper.SetDefault("filter.skip_extensions", []string{
+		".jpg", ".jpeg", ".png", ".gif", ".webp", ".svg", ".ico", ".bmp",
+		".zip", ".rar", ".7z", ".tar", ".gz", ".bz2",
+		".pdf", ".doc", ".docx", ".xls", ".xlsx", ".ppt", ".pptx",
+		".mp3", ".mp4", ".avi", ".mov", ".webm", ".exe", ".dmg", ".iso",
+	})
+	viper.SetDefault("filter.max_url_length", DefaultMaxURLLength)
+
+	viper.SetDefault("links.follow", []string{"navigation"})
+
+	// Только параметры отслеживания: параметры сессий (sid, phpsessid…) на части сайтов
+	// меняют содержимое страницы, поэтому удаляются лишь по явной настройке.
+	viper.SetDefault("normalize.strip_params", []string{
+		"utm_*", "fbclid", "gclid", "yclid", "dclid", "msclkid", "mc_cid", "mc_eid", "_openstat",
+	})
+
+	viper.SetDefault("mode", ModeCrawl)
+	viper.SetDefault("check_links.format", "console")
+	viper.SetDefault("check_links.output", "")
+
+	viper.SetDefault("metrics.addr", "")
+	viper.SetDefault("server.addr", ":8080")
+	viper.SetDefault("server.job_ttl", DefaultJobTTL)
+
 	viper.SetDefault("worker_count", DefaultWorkerCount)
+	viper.SetDefault("limits.max_pages", 0)
+	viper.SetDefault("limits.max_duration", 0)
+	viper.SetDefault("limits.max_pages_per_host", 0)
+	viper.SetDefault("limits.max_bytes", 0)
+	viper.SetDefault("revisit.interval", 0)
+	viper.SetDefault("revisit.sitemap_changefreq", false)
 	viper.SetDefault("max_depth", DefaultMaxDepth)
 	viper.SetDefault("same_host", true)
+	viper.SetDefault("dedup_canonical", false)
 	viper.SetDefault("log.level", "info")
 
-	pflag.String("start_url", "", "Стартовый URL для краулинга (обязательно)")
-	pflag.Bool("same_host", viper.GetBool("same_host"), "Ограничить обход только стартовым хостом")
+	pflag.String("mode", viper.GetString("mode"), "Режим: crawl — обход, check-links — поиск битых ссылок, server — HTTP API заданий")
+	pflag.String("check_links.format", viper.GetString("check_links.format"), "Формат отчета о битых ссылках (console, json, csv)")
+	pflag.String("check_links.output", "", "Файл отчета о битых ссылках (по умолчанию stdout)")
+	pflag.StringArray("start_url", nil, "Стартовый URL для краулинга; флаг можно повторять")
+	pflag.String("seed_file", "", "Файл со стартовыми URL, по одному на строку (# — комментарий)")
+	pflag.Bool("same_host", viper.GetBool("same_host"), "Ограничить обход только стартовым хостом (устарело, см. --scope)")
+	pflag.String("scope", "", "Область обхода: host, domain, subdomains, path-prefix, any")
+	pflag.StringSlice("scope_hosts", nil, "Дополнительные хосты, которые обходятся всегда (можно *.example.com)")
 	pflag.Int("max_depth", viper.GetInt("max_depth"), "Максимальная глубина обхода")
 	pflag.Int("worker_count", viper.GetInt("worker_count"), "Количество одновременных воркеров")
+	pflag.Int64("limits.max_pages", viper.GetInt64("limits.max_pages"), "Остановить обход после стольких страниц (0 — без лимита)")
+	pflag.Duration("limits.max_duration", viper.GetDuration("limits.max_duration"), "Остановить обход через это время (0 — без лимита)")
+	pflag.Int64("limits.max_pages_per_host", viper.GetInt64("limits.max_pages_per_host"), "Сколько страниц загружать с одного хоста (0 — без лимита)")
+	pflag.Int64("limits.max_bytes", viper.GetInt64("limits.max_bytes"), "Остановить обход после стольких загруженных байт (0 — без лимита)")
+	pflag.Duration("revisit.interval", viper.GetDuration("revisit.interval"), "Через сколько URL снова обходится (0 — один раз)")
 	pflag.Bool("force_recrawl", false, "Очистить состояние перед запуском для принудительного повторного обхода")
+	pflag.Bool("resume", false, "Продолжить прерванный обход с задач, сохраненных во фронтире")
+	pflag.Bool("retry_failed", false, "Повторить URL, которые не удалось обработать в прошлых запусках")
+	pflag.Bool("incremental", false, "Повторный обход: разбирать только изменившиеся страницы (ETag, Last-Modified)")
+	pflag.Bool("distributed", false, "Делить обход с другими экземплярами через общий фронтир в Redis")
+	pflag.Bool("dedup_canonical", viper.GetBool("dedup_canonical"), "Считать дубликатом страницу, чей canonical уже встречался")
+	pflag.String("worker_id", "", "Идентификатор экземпляра в логах и результатах (по умолчанию hostname-pid)")
+	pflag.String("job_id", "", "Идентификатор обхода: ключи в Redis и отметка результатов (по умолчанию генерируется, ключи общие)")
+	pflag.String("frontier.backend", viper.GetString("frontier.backend"), "Где хранить очередь обхода (memory, redis)")
+	pflag.String("frontier.order", viper.GetString("frontier.order"), "Порядок обхода (bfs, dfs, priority)")
 	pflag.Duration("http.timeout", viper.GetDuration("http.timeout"), "Таймаут для HTTP запросов")
+	pflag.Int("http.retry.max_attempts", viper.GetInt("http.retry.max_attempts"), "Максимум попыток загрузки одного URL")
 	pflag.String("mongo.uri", viper.GetString("mongo.uri"), "URI для подключения к MongoDB")
 	pflag.String("redis.addr", viper.GetString("redis.addr"), "Адрес для подключения к Redis (host:port)")
+	pflag.Bool("robots.enabled", viper.GetBool("robots.enabled"), "Соблюдать правила robots.txt")
+	pflag.Bool("robots.ignore_directives", viper.GetBool("robots.ignore_directives"), "Игнорировать nofollow, meta robots и X-Robots-Tag (для аудитов)")
+	pflag.String("robots.user_agent", viper.GetString("robots.user_agent"), "User-Agent запросов и выбора группы правил robots.txt")
+	pflag.Bool("sitemap.enabled", viper.GetBool("sitemap.enabled"), "Добавить в очередь URL из sitemap сайта")
+	pflag.Bool("sitemap.only", viper.GetBool("sitemap.only"), "Обходить только URL из sitemap, не переходя по ссылкам")
+	pflag.Duration("politeness.delay", viper.GetDuration("politeness.delay"), "Минимальная пауза между запросами к одному хосту")
+	pflag.Int("politeness.max_concurrency", viper.GetInt("politeness.max_concurrency"), "Максимум одновременных запросов к одному хосту")
+	pflag.Bool("content.store_html", viper.GetBool("content.store_html"), "Сохранять сжатый HTML страниц")
+	pflag.Bool("content.extract_metadata", viper.GetBool("content.extract_metadata"), "Сохранять заголовок, описание, заголовки и текст страниц")
+	pflag.StringSlice("links.follow", viper.GetStringSlice("links.follow"), "Виды ссылок для обхода: navigation, form, resource, embed, redirect")
+	pflag.String("server.addr", viper.GetString("server.addr"), "Адрес HTTP API в режиме server")
+	pflag.Duration("server.job_ttl", viper.GetDuration("server.job_ttl"), "Сколько хранить завершенные задания в списке (0 — всегда)")
+	pflag.String("metrics.addr", "", "Адрес HTTP-сервера с /metrics для Prometheus, например :9090")
 	pflag.String("log.level", viper.GetString("log.level"), "Уровень логирования (debug, info, warn, error)")
 
 	pflag.Parse()
@@ -93,10 +373,185 @@ func New() (*Config, error) {
 	if err := viper.Unmarshal(&cfg); err != nil {
 		return nil, err
 	}
+	cfg.settings = redact(viper.AllSettings())
 
-	if cfg.StartURL == "" {
-		return nil, errors.New("необходимо указать стартовый URL через флаг -start_url или в конфиге")
+	if cfg.SeedFile != "" {
+		seeds, err := readSeedFile(cfg.SeedFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.StartURLs = append(cfg.StartURLs, seeds...)
+	}
+	if err := cfg.prepare(true); err != nil {
+		return nil, err
 	}
-
 	return &cfg, nil
 }
+
+// Snapshot возвращает настройки обхода в том виде, в котором они заданы
+// (файл, окружение, флаги, переопределения задания), без паролей.
+func (c *Config) Snapshot() map[string]any {
+	return c.settings
+}
+
+// prepare дополняет производные значения и проверяет согласованность настроек.
+// sharedKeys — без заданного job_id оставить стейт и фронтир в общих ключах
+// (для запусков из командной строки).
+func (c *Config) prepare(sharedKeys bool) error {
+	// В режиме server стартовые URL и идентификаторы задаются в каждом задании.
+	if c.Mode == ModeServer {
+		return c.validate()
+	}
+	if len(c.StartURLs) == 0 {
+		return errors.New("необходимо указать стартовый URL через флаг --start_url, seed_file или в конфиге")
+	}
+	if err := c.prepareJobID(sharedKeys); err != nil {
+		return err
+	}
+	return c.validate()
+}
+
+// prepareJobID генерирует идентификатор обхода, если он не задан: им отмечаются
+// результаты и запись о запуске. Заданный job_id выносит стейт и фронтир в ключи Redis
+// с суффиксом job_id, чтобы обходы не мешали друг другу. Без него обход работает
+// с общими ключами без суффикса (sharedKeys): повторный запуск не обходит уже посещенные
+// URL, а --resume и --retry_failed продолжают его. Задания сервера получают собственные
+// ключи и со сгенерированным идентификатором: сервер удаляет их по завершении, иначе
+// каждый запуск оставлял бы в Redis новые ключи.
+// Incremental и revisit сравнивают запуск с прошлыми, поэтому требуют явный job_id.
+func (c *Config) prepareJobID(sharedKeys bool) error {
+	if c.JobID == "" {
+		if c.Incremental {
+			return errors.New("для --incremental нужен --job_id обхода, с которым сравнивать страницы")
+		}
+		if c.Revisit.Enabled() {
+			return errors.New("для revisit нужен --job_id: время посещений хранится в ключах обхода")
+		}
+
+		id, err := newJobID()
+		if err != nil {
+			return err
+		}
+		c.JobID = id
+		c.SharedKeys = sharedKeys
+	}
+	if !validJobID(c.JobID) {
+		return fmt.Errorf("некорректный job_id %q: допустимы латинские буквы, цифры, «.», «_» и «-»", c.JobID)
+	}
+	if c.SharedKeys {
+		return nil
+	}
+
+	c.Redis.SetKey += ":" + c.JobID
+	c.Frontier.KeyPrefix += ":" + c.JobID
+	return nil
+}
+
+// validate проверяет согласованность настроек и дополняет значения по умолчанию.
+func (c *Config) validate() error {
+	// Старый флаг same_host продолжает работать, если scope не задан явно.
+	if c.Scope == "" {
+		c.Scope = ScopeHost
+		if !c.SameHost {
+			c.Scope = ScopeAny
+		}
+	}
+
+	if c.Frontier.Backend != FrontierMemory && c.Frontier.Backend != FrontierRedis {
+		return fmt.Errorf("неизвестный frontier.backend %q, допустимо: memory, redis", c.Frontier.Backend)
+	}
+	if c.Mode != ModeCrawl && c.Mode != ModeCheckLinks && c.Mode != ModeServer {
+		return fmt.Errorf("неизвестный mode %q, допустимо: crawl, check-links, server", c.Mode)
+	}
+	switch c.Frontier.Order {
+	case OrderBFS, OrderDFS, OrderPriority:
+	default:
+		return fmt.Errorf("неизвестный frontier.order %q, допустимо: bfs, dfs, priority", c.Frontier.Order)
+	}
+	if c.Resume && c.ForceRecrawl {
+		return errors.New("флаги --resume и --force_recrawl нельзя использовать вместе")
+	}
+	if c.Distributed && c.Frontier.Backend == FrontierMemory {
+		return errors.New("для --distributed нужен frontier.backend: redis")
+	}
+	if c.WorkerID == "" {
+		c.WorkerID = defaultWorkerID()
+	}
+	// Режим «только sitemap» без загрузки sitemap не имеет смысла.
+	if c.Sitemap.Only {
+		c.Sitemap.Enabled = true
+	}
+	if c.Resume && c.Frontier.Backend == FrontierMemory {
+		return errors.New("для --resume нужен frontier.backend: redis, очередь в памяти не переживает перезапуск")
+	}
+	if c.RetryFailed && c.Frontier.Backend == FrontierMemory {
+		return errors.New("для --retry_failed нужен frontier.backend: redis, очередь в памяти не переживает перезапуск")
+	}
+	if c.RetryFailed && c.ForceRecrawl {
+		return errors.New("флаги --retry_failed и --force_recrawl нельзя использовать вместе")
+	}
+	if c.Incremental && c.ForceRecrawl {
+		return errors.New("флаги --incremental и --force_recrawl нельзя использовать вместе: " +
+			"--force_recrawl загружает все страницы заново")
+	}
+
+	return nil
+}
+
+// readSeedFile читает стартовые URL из файла: по одному на строку,
+// пустые строки и строки, начинающиеся с #, пропускаются.
+func readSeedFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать файл стартовых URL: %w", err)
+	}
+
+	var seeds []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		seeds = append(seeds, line)
+	}
+	return seeds, nil
+}
+
+// redact убирает из настроек пароли подключения к базам.
+func redact(settings map[string]any) map[string]any {
+	if mongo, ok := settings["mongo"].(map[string]any); ok {
+		delete(mongo, "uri")
+	}
+	if redis, ok := settings["redis"].(map[string]any); ok {
+		delete(redis, "password")
+	}
+	return settings
+}
+
+func newJobID() (string, error) {
+	b := make([]byte, jobIDBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("не удалось сгенерировать идентификатор обхода: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// validJobID проверяет, что идентификатор можно использовать в ключах Redis и URL API.
+func validJobID(id string) bool {
+	for _, r := range id {
+		ok := r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '_' || r == '-'
+		if !ok {
+			return false
+		}
+	}
+	return id != ""
+}
+
+// defaultWorkerID строит идентификатор, уникальный для процесса на машине.
+func defaultWorkerID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
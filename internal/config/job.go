@@ -0,0 +1,67 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// jobKeys — ключи верхнего уровня, которые задание может переопределить.
+// Подключения к базам, режим работы и флаги запуска остаются общими для сервера.
+func jobKeys() map[string]bool {
+	return map[string]bool{
+		"start_url": true, "scope": true, "scope_hosts": true, "same_host": true,
+		"max_depth": true, "worker_count": true, "limits": true, "dedup_canonical": true,
+		"http": true, "frontier": true, "robots": true, "sitemap": true, "politeness": true,
+		"content": true, "filter": true, "links": true, "normalize": true, "job_id": true,
+		"incremental": true, "revisit": true,
+	}
+}
+
+// ForJob возвращает настройки задания: общий конфиг с переопределенными ключами
+// overrides (в том же виде, что и в config.yaml). Если job_id не задан, он генерируется.
+// Стейт и фронтир задания получают собственные ключи в Redis с суффиксом job_id,
+// чтобы задания не мешали друг другу; сервер удаляет их по завершении задания.
+func (c *Config) ForJob(overrides map[string]any) (*Config, error) {
+	allowed := jobKeys()
+	for key := range overrides {
+		if !allowed[strings.ToLower(key)] {
+			return nil, fmt.Errorf("ключ %q нельзя задать в настройках задания", key)
+		}
+	}
+	if _, ok := overrides["start_url"]; !ok {
+		return nil, errors.New("в настройках задания нужен start_url")
+	}
+
+	// Стартовые URL и идентификатор берутся только из задания, а не из общего конфига.
+	base := viper.AllSettings()
+	delete(base, "start_url")
+	delete(base, "seed_file")
+	delete(base, "job_id")
+
+	v := viper.New()
+	if err := v.MergeConfigMap(base); err != nil {
+		return nil, fmt.Errorf("не удалось скопировать общий конфиг: %w", err)
+	}
+	if err := v.MergeConfigMap(overrides); err != nil {
+		return nil, fmt.Errorf("некорректные настройки задания: %w", err)
+	}
+	// Задание — это обычный обход; флаги запуска сервера на него не действуют.
+	v.Set("mode", ModeCrawl)
+	for _, flag := range []string{"force_recrawl", "resume", "retry_failed", "distributed"} {
+		v.Set(flag, false)
+	}
+
+	var job Config
+	if err := v.Unmarshal(&job); err != nil {
+		return nil, fmt.Errorf("некорректные настройки задания: %w", err)
+	}
+
+	job.settings = redact(v.AllSettings())
+	if err := job.prepare(false); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
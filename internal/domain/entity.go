@@ -1,8 +1,97 @@
 package domain
 
+import "time"
+
+// CrawlStatus — итог обработки URL краулером.
+type CrawlStatus string
+
+const (
+	StatusCrawled CrawlStatus = "crawled"
+	StatusSkipped CrawlStatus = "skipped"
+	// StatusDuplicate — страница загружена, но ее canonical указывает на уже
+	// известный URL, поэтому ссылки с нее не обходятся.
+	StatusDuplicate CrawlStatus = "duplicate"
+	// StatusFailed — URL не удалось загрузить или разобрать, подробности в CrawledData.Error.
+	StatusFailed CrawlStatus = "failed"
+)
+
+// Причины, по которым URL был пропущен без загрузки.
+const (
+	SkipReasonRobots = "robots.txt"
+)
+
+// Ordering — порядок, в котором фронтир выдает задачи.
+type Ordering string
+
+const (
+	OrderBFS      Ordering = "bfs"      // В ширину: задачи выдаются в порядке добавления.
+	OrderDFS      Ordering = "dfs"      // В глубину: первой выдается последняя добавленная задача.
+	OrderPriority Ordering = "priority" // Сначала задачи с наибольшим Task.Priority.
+)
+
+// Task — задача краулера: URL, который нужно обойти.
+type Task struct {
+	URL       string `json:"url"`
+	Depth     int    `json:"depth"`
+	ParentURL string `json:"parent_url,omitempty"`
+	// Seed — стартовый URL, с которого начался путь к этой задаче. По нему
+	// выбирается область обхода для найденных ссылок.
+	Seed string `json:"seed,omitempty"`
+	// Sitemap заполнен, если URL взят из sitemap.
+	Sitemap *SitemapMeta `json:"sitemap,omitempty"`
+	// AnchorText — текст ссылки, по которой найден URL.
+	AnchorText string `json:"anchor_text,omitempty"`
+	// Priority — оценка задачи для порядка OrderPriority.
+	Priority float64 `json:"priority,omitempty"`
+	// Attempts — сколько раз URL уже пытались загрузить в прошлых запусках.
+	Attempts int `json:"attempts,omitempty"`
+	// Lease — токен аренды, выданный фронтиром при Pop. По нему Done и Fail
+	// проверяют, что задача все еще за этим воркером; в описании задачи не хранится.
+	Lease string `json:"-"`
+}
+
 type CrawledData struct {
-	URL        string   `bson:"url"`
-	Depth      int      `bson:"depth"`
-	FoundOn    string   `bson:"found_on"` // URL, на котором была найдена эта страница
-	FoundLinks []string `bson:"found_links"`
+	URL        string       `bson:"url"`
+	Depth      int          `bson:"depth"`
+	FoundOn    string       `bson:"found_on"` // URL, на котором была найдена эта страница
+	FoundLinks []string     `bson:"found_links"`
+	Status     CrawlStatus  `bson:"status"`
+	SkipReason string       `bson:"skip_reason,omitempty"`
+	WorkerID   string       `bson:"worker_id,omitempty"` // Экземпляр краулера, обработавший URL.
+	JobID      string       `bson:"job_id"`              // Задание; пишется и пустым — по нему ищет Save.
+	Seed       string       `bson:"seed,omitempty"`      // Стартовый URL, с которого найдена страница.
+	Sitemap    *SitemapMeta `bson:"sitemap,omitempty"`   // Атрибуты URL, если он взят из sitemap.
+
+	// Данные HTTP-ответа, заполняются только для загруженных страниц.
+	StatusCode    int                 `bson:"status_code,omitempty"`
+	FinalURL      string              `bson:"final_url,omitempty"`
+	RedirectChain []string            `bson:"redirect_chain,omitempty"`
+	ContentType   string              `bson:"content_type,omitempty"`
+	ContentLength int64               `bson:"content_length,omitempty"`
+	Headers       map[string][]string `bson:"headers,omitempty"`
+	FetchDuration time.Duration       `bson:"fetch_duration,omitempty"`
+
+	// Для инкрементального обхода: валидаторы кэша, хеш тела и время последней
+	// проверки (в том числе ответом 304, при котором остальные поля не меняются).
+	ETag         string    `bson:"etag,omitempty"`
+	LastModified string    `bson:"last_modified,omitempty"`
+	ContentHash  string    `bson:"content_hash,omitempty"` // SHA-256 тела ответа.
+	LastChecked  time.Time `bson:"last_checked,omitempty"`
+
+	Canonical string `bson:"canonical,omitempty"` // Нормализованный URL из <link rel="canonical">.
+
+	Error *Failure `bson:"error,omitempty"` // Заполняется для StatusFailed.
+
+	// Указания для роботов из meta robots и X-Robots-Tag.
+	NoIndex  bool `bson:"noindex,omitempty"`
+	NoFollow bool `bson:"nofollow,omitempty"`
+
+	// Links — все ссылки страницы с видом, текстом и rel, в том числе те,
+	// по которым обход не идет (для аудита ресурсов).
+	Links []Link `bson:"links,omitempty"`
+
+	// Содержимое страницы, сохраняется, только если это включено в конфиге.
+	Page          *Page  `bson:"page,omitempty"`
+	HTML          []byte `bson:"html,omitempty"` // Исходный HTML, сжатый gzip.
+	HTMLTruncated bool   `bson:"html_truncated,omitempty"`
 }
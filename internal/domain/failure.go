@@ -0,0 +1,31 @@
+package domain
+
+// ErrorClass — класс ошибки, из-за которой URL не удалось обработать.
+type ErrorClass string
+
+const (
+	ErrorDNS        ErrorClass = "dns"
+	ErrorTimeout    ErrorClass = "timeout"
+	ErrorTLS        ErrorClass = "tls"
+	ErrorHTTPStatus ErrorClass = "http_status" // Сервер ответил неуспешным статус-кодом.
+	ErrorNetwork    ErrorClass = "network"     // Соединение отклонено, разорвано и т.п.
+	ErrorParse      ErrorClass = "parse"
+	ErrorOther      ErrorClass = "other"
+)
+
+// Failure — описание неудачной обработки URL.
+type Failure struct {
+	Class    ErrorClass `bson:"class"`
+	Message  string     `bson:"message"`
+	Attempts int        `bson:"attempts"`
+	// StatusCode и RedirectChain сохраняются в одноименных полях CrawledData.
+	StatusCode    int      `bson:"-"`
+	RedirectChain []string `bson:"-"`
+}
+
+// FailureError — ошибка, которая сама знает свой класс и число попыток.
+// Ее возвращают Fetcher и этапы обработки страницы.
+type FailureError interface {
+	error
+	Failure() Failure
+}
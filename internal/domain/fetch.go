@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// FetchResult — результат загрузки страницы. Body нужно закрыть после чтения.
+type FetchResult struct {
+	URL           string   // Запрошенный URL.
+	FinalURL      string   // URL после всех редиректов.
+	RedirectChain []string // URL, которые вернули редирект, в порядке прохождения.
+	StatusCode    int
+	Header        http.Header
+	ContentType   string
+	ContentLength int64         // -1, если сервер не сообщил длину.
+	Duration      time.Duration // Время от отправки запроса до получения заголовков ответа.
+	Body          io.ReadCloser
+}
+
+// Pace ждет, пока к хосту запроса можно обратиться с учетом ограничений нагрузки,
+// и возвращает функцию, которую нужно вызвать после запроса. Загрузчик вызывает ее
+// перед каждой попыткой, включая повторы.
+type Pace func(ctx context.Context) (release func(), err error)
+
+// Validators — валидаторы кэша из прошлого ответа. С ними запрос становится
+// условным: сервер отвечает 304 Not Modified, если страница не менялась.
+type Validators struct {
+	ETag         string // Отправляется в If-None-Match.
+	LastModified string // Отправляется в If-Modified-Since.
+}
+
+// Empty сообщает, что валидаторов нет и запрос будет обычным.
+func (v Validators) Empty() bool {
+	return v.ETag == "" && v.LastModified == ""
+}
@@ -0,0 +1,13 @@
+package domain
+
+// BrokenLink — ссылка, которая в режиме проверки ссылок вернула ошибку.
+type BrokenLink struct {
+	Source        string     `json:"source"` // Страница, на которой найдена ссылка.
+	URL           string     `json:"url"`
+	Kind          LinkKind   `json:"kind"`
+	Anchor        string     `json:"anchor,omitempty"`
+	StatusCode    int        `json:"status_code,omitempty"` // 0 — ответа не было (DNS, таймаут и т.п.).
+	RedirectChain []string   `json:"redirect_chain,omitempty"`
+	ErrorClass    ErrorClass `json:"error_class"`
+	Error         string     `json:"error"`
+}
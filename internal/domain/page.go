@@ -0,0 +1,62 @@
+package domain
+
+import "slices"
+
+// Page — данные, извлеченные из HTML-страницы.
+type Page struct {
+	Title       string    `bson:"title,omitempty"`
+	Description string    `bson:"description,omitempty"`
+	Headings    []Heading `bson:"headings,omitempty"`
+	Canonical   string    `bson:"canonical,omitempty"`
+	Language    string    `bson:"language,omitempty"`
+	Text        string    `bson:"text,omitempty"` // Видимый текст без скриптов и стилей.
+	// TextTruncated — текст обрезан до content.max_text_size.
+	TextTruncated bool `bson:"text_truncated,omitempty"`
+
+	Links      []Link           `bson:"-"` // Ссылки хранятся в CrawledData.FoundLinks.
+	Directives RobotsDirectives `bson:"-"` // Из <meta name="robots">.
+}
+
+// Heading — заголовок h1–h6.
+type Heading struct {
+	Level int    `bson:"level"`
+	Text  string `bson:"text"`
+}
+
+// LinkKind — вид ссылки по тому, как страница ее использует.
+type LinkKind string
+
+const (
+	LinkNavigation LinkKind = "navigation" // <a>, <area>, <link rel="next"> и т.п.
+	LinkForm       LinkKind = "form"       // action формы с методом GET.
+	LinkResource   LinkKind = "resource"   // Стили, скрипты, изображения, CSS url().
+	LinkEmbed      LinkKind = "embed"      // <iframe>, <embed>, <object>.
+	LinkRedirect   LinkKind = "redirect"   // <meta http-equiv="refresh">.
+)
+
+// Link — ссылка, найденная на странице.
+type Link struct {
+	URL  string   `bson:"url"`
+	Kind LinkKind `bson:"kind"`
+	Text string   `bson:"text,omitempty"` // Текст ссылки или alt изображения.
+	Rel  []string `bson:"rel,omitempty"`  // Значения атрибута rel в нижнем регистре.
+}
+
+// NoFollow сообщает, что автор страницы просит не переходить по ссылке.
+func (l Link) NoFollow() bool {
+	return slices.Contains(l.Rel, "nofollow")
+}
+
+// RobotsDirectives — указания для роботов из <meta name="robots"> или X-Robots-Tag.
+type RobotsDirectives struct {
+	NoIndex  bool
+	NoFollow bool
+}
+
+// Merge объединяет указания: запрет из любого источника сохраняется.
+func (d RobotsDirectives) Merge(other RobotsDirectives) RobotsDirectives {
+	return RobotsDirectives{
+		NoIndex:  d.NoIndex || other.NoIndex,
+		NoFollow: d.NoFollow || other.NoFollow,
+	}
+}
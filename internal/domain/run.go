@@ -0,0 +1,41 @@
+package domain
+
+import "time"
+
+// RunStatus — итог запуска обхода.
+type RunStatus string
+
+const (
+	RunRunning     RunStatus = "running"
+	RunCompleted   RunStatus = "completed"
+	RunStopped     RunStatus = "stopped"     // Остановлен по лимиту, очередь осталась во фронтире.
+	RunInterrupted RunStatus = "interrupted" // Прерван сигналом завершения.
+	RunPaused      RunStatus = "paused"
+	RunCancelled   RunStatus = "cancelled"
+	RunFailed      RunStatus = "failed"
+)
+
+// Run — запись о запуске обхода: с какими настройками, когда и с каким итогом.
+// У одного обхода (JobID) может быть несколько запусков, например после --resume.
+type Run struct {
+	ID         string         `bson:"_id"`
+	JobID      string         `bson:"job_id"`
+	Mode       string         `bson:"mode"`
+	WorkerID   string         `bson:"worker_id,omitempty"`
+	StartURLs  []string       `bson:"start_urls"`
+	Config     map[string]any `bson:"config"`              // Настройки без паролей.
+	Overrides  map[string]any `bson:"overrides,omitempty"` // Настройки задания сервера из запроса.
+	Status     RunStatus      `bson:"status"`
+	Error      string         `bson:"error,omitempty"`
+	StartedAt  time.Time      `bson:"started_at"`
+	FinishedAt time.Time      `bson:"finished_at,omitempty"`
+	Stats      RunStats       `bson:"stats"`
+}
+
+// RunStats — итоговая статистика запуска.
+type RunStats struct {
+	Pages      int64         `bson:"pages"`
+	Bytes      int64         `bson:"bytes"`
+	Duration   time.Duration `bson:"duration"`
+	StopReason string        `bson:"stop_reason,omitempty"` // Лимит, остановивший обход.
+}
@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+// SitemapMeta — атрибуты URL из sitemap.
+type SitemapMeta struct {
+	Source     string    `json:"source" bson:"source"` // Sitemap, в котором найден URL.
+	LastMod    time.Time `json:"lastmod,omitempty" bson:"lastmod,omitempty"`
+	Priority   float64   `json:"priority,omitempty" bson:"priority,omitempty"` // 0 — не указан.
+	ChangeFreq string    `json:"changefreq,omitempty" bson:"changefreq,omitempty"`
+}
+
+// SitemapEntry — URL, найденный в sitemap.
+type SitemapEntry struct {
+	URL  string
+	Meta SitemapMeta
+}
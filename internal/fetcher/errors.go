@@ -0,0 +1,171 @@
+package fetcher
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"justycrawler/internal/domain"
+)
+
+// RetryableError — временная ошибка загрузки (5xx, 429, таймаут, разрыв соединения).
+// Если она вернулась из Fetch, значит, все попытки исчерпаны.
+type RetryableError struct {
+	URL        string
+	StatusCode int           // 0, если ответ не был получен.
+	RetryAfter time.Duration // Значение заголовка Retry-After, если сервер его прислал.
+	// RedirectChain — редиректы, пройденные до ответа с ошибкой.
+	RedirectChain []string
+	Attempts      int
+	Err           error
+}
+
+func (e *RetryableError) Error() string {
+	return fmt.Sprintf("временная ошибка загрузки %s (попыток: %d): %v", e.URL, e.Attempts, e.Err)
+}
+
+func (e *RetryableError) Unwrap() error {
+	return e.Err
+}
+
+// Failure реализует интерфейс domain.FailureError.
+func (e *RetryableError) Failure() domain.Failure {
+	return domain.Failure{
+		Class:         errorClass(e.StatusCode, e.Err),
+		Message:       e.Error(),
+		Attempts:      e.Attempts,
+		StatusCode:    e.StatusCode,
+		RedirectChain: e.RedirectChain,
+	}
+}
+
+// PermanentError — ошибка, которую повторный запрос не исправит (4xx, DNS, TLS и т.п.).
+type PermanentError struct {
+	URL        string
+	StatusCode int // 0, если ответ не был получен.
+	// RedirectChain — редиректы, пройденные до ответа с ошибкой.
+	RedirectChain []string
+	Attempts      int
+	Err           error
+}
+
+func (e *PermanentError) Error() string {
+	return fmt.Sprintf("постоянная ошибка загрузки %s: %v", e.URL, e.Err)
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// Failure реализует интерфейс domain.FailureError.
+func (e *PermanentError) Failure() domain.Failure {
+	return domain.Failure{
+		Class:         errorClass(e.StatusCode, e.Err),
+		Message:       e.Error(),
+		Attempts:      e.Attempts,
+		StatusCode:    e.StatusCode,
+		RedirectChain: e.RedirectChain,
+	}
+}
+
+// errorClass определяет класс ошибки загрузки для отчетов о битых URL.
+func errorClass(statusCode int, err error) domain.ErrorClass {
+	var (
+		dnsErr       *net.DNSError
+		netErr       net.Error
+		opErr        *net.OpError
+		certErr      *tls.CertificateVerificationError
+		recordErr    tls.RecordHeaderError
+		alertErr     tls.AlertError
+		authorityErr x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		invalidErr   x509.CertificateInvalidError
+	)
+	switch {
+	case statusCode != 0:
+		return domain.ErrorHTTPStatus
+	case errors.As(err, &dnsErr):
+		return domain.ErrorDNS
+	case errors.As(err, &certErr), errors.As(err, &recordErr), errors.As(err, &alertErr),
+		errors.As(err, &authorityErr), errors.As(err, &hostnameErr), errors.As(err, &invalidErr):
+		return domain.ErrorTLS
+	case errors.As(err, &netErr) && netErr.Timeout(), errors.Is(err, context.DeadlineExceeded):
+		return domain.ErrorTimeout
+	case errors.As(err, &opErr), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.ECONNREFUSED):
+		return domain.ErrorNetwork
+	default:
+		return domain.ErrorOther
+	}
+}
+
+// classifyTransportError решает, стоит ли повторять запрос после сетевой ошибки.
+func classifyTransportError(url string, err error) error {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.Canceled):
+		// Отмена сверху — не ошибка сайта, повторять нельзя.
+		return err
+	case errors.As(err, &netErr) && netErr.Timeout(),
+		errors.Is(err, context.DeadlineExceeded),
+		errors.Is(err, syscall.ECONNRESET),
+		errors.Is(err, syscall.ECONNABORTED),
+		errors.Is(err, syscall.EPIPE),
+		errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, io.EOF):
+		return &RetryableError{URL: url, Err: err}
+	default:
+		return &PermanentError{URL: url, Err: err}
+	}
+}
+
+// classifyStatus превращает неуспешный статус-код в типизированную ошибку.
+func classifyStatus(url string, resp *http.Response) error {
+	err := fmt.Errorf("неожиданный статус-код %d", resp.StatusCode)
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError {
+		return &RetryableError{
+			URL:           url,
+			StatusCode:    resp.StatusCode,
+			RetryAfter:    parseRetryAfter(resp.Header.Get("Retry-After")),
+			RedirectChain: redirectChain(resp),
+			Err:           err,
+		}
+	}
+	return &PermanentError{URL: url, StatusCode: resp.StatusCode, RedirectChain: redirectChain(resp), Err: err}
+}
+
+// parseRetryAfter понимает обе формы заголовка: число секунд и HTTP-дату.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0)
+	}
+	return 0
+}
+
+// setAttempts записывает в типизированную ошибку, сколько попыток было сделано.
+func setAttempts(err error, attempts int) {
+	var retryErr *RetryableError
+	if errors.As(err, &retryErr) {
+		retryErr.Attempts = attempts
+		return
+	}
+	var permErr *PermanentError
+	if errors.As(err, &permErr) {
+		permErr.Attempts = attempts
+	}
+}
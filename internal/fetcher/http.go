@@ -2,43 +2,234 @@ package fetcher
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"math/rand/v2"
 	"net/http"
+	"slices"
+	"sync"
 	"time"
+
+	"justycrawler/internal/domain"
+	"justycrawler/internal/metrics"
 )
 
-// HTTPFetcher — реализация Fetcher через net/http с таймаутом.
+// RetryPolicy — настройки повторных попыток.
+type RetryPolicy struct {
+	MaxAttempts int           // Сколько всего попыток делать для одного URL (1 — без повторов).
+	BaseDelay   time.Duration // Пауза перед первым повтором, дальше она удваивается.
+	MaxDelay    time.Duration // Верхняя граница паузы; при большем Retry-After повторов нет.
+}
+
+// HTTPFetcher — реализация Fetcher через net/http с таймаутом и повторными попытками.
 type HTTPFetcher struct {
-	client *http.Client
+	client    *http.Client
+	userAgent string
+	retry     RetryPolicy
+	metrics   *metrics.Metrics
 }
 
-// New создает новый HTTPFetcher с указанным таймаутом.
-func New(timeout time.Duration) *HTTPFetcher {
+// New создает новый HTTPFetcher с указанным таймаутом и политикой повторов.
+// userAgent отправляется с каждым запросом — тот же, по которому выбираются
+// правила robots.txt. Метрики m могут быть nil.
+func New(timeout time.Duration, userAgent string, retry RetryPolicy, m *metrics.Metrics) *HTTPFetcher {
+	retry.MaxAttempts = max(retry.MaxAttempts, 1)
 	return &HTTPFetcher{
 		client: &http.Client{
 			Timeout: timeout,
 		},
+		userAgent: userAgent,
+		retry:     retry,
+		metrics:   m,
 	}
 }
 
 // Fetch реализует интерфейс crawler.Fetcher.
-func (f *HTTPFetcher) Fetch(ctx context.Context, url string) (io.ReadCloser, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+// Временные ошибки повторяются с экспоненциальной паузой, итоговая ошибка —
+// *RetryableError или *PermanentError. С непустыми validators запрос условный,
+// и ответ 304 Not Modified возвращается как результат с пустым телом.
+func (f *HTTPFetcher) Fetch(
+	ctx context.Context,
+	url string,
+	validators domain.Validators,
+	pace domain.Pace,
+) (*domain.FetchResult, error) {
+	accept := okOnly
+	if !validators.Empty() {
+		accept = okOrNotModified
+	}
+	return f.withRetry(ctx, pace, func() (*domain.FetchResult, error) {
+		return f.fetchOnce(ctx, http.MethodGet, url, validators, accept)
+	})
+}
+
+// Check реализует интерфейс crawler.LinkChecker. Сначала ссылка проверяется
+// запросом HEAD, а если он не удался — запросом GET: часть серверов не поддерживает
+// HEAD или отвечает на него ошибкой. Успешным считается любой ответ до 400,
+// тело ответа не читается.
+func (f *HTTPFetcher) Check(ctx context.Context, url string, pace domain.Pace) error {
+	result, err := f.withRetry(ctx, pace, func() (*domain.FetchResult, error) {
+		return f.fetchOnce(ctx, http.MethodHead, url, domain.Validators{}, belowClientError)
+	})
+	if err != nil && ctx.Err() == nil {
+		result, err = f.withRetry(ctx, pace, func() (*domain.FetchResult, error) {
+			return f.fetchOnce(ctx, http.MethodGet, url, domain.Validators{}, belowClientError)
+		})
+	}
 	if err != nil {
-		return nil, fmt.Errorf("не удалось создать запрос для %s: %w", url, err)
+		return err
+	}
+	_ = result.Body.Close()
+	return nil
+}
+
+// withRetry повторяет запрос по политике повторов, пока ошибка временная. Перед каждой
+// попыткой хост занимается через pace, поэтому повторы соблюдают паузу между запросами
+// к хосту, а не только собственную паузу повтора.
+func (f *HTTPFetcher) withRetry(
+	ctx context.Context,
+	pace domain.Pace,
+	do func() (*domain.FetchResult, error),
+) (*domain.FetchResult, error) {
+	for attempt := 1; ; attempt++ {
+		release := func() {}
+		if pace != nil {
+			var err error
+			if release, err = pace(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		result, err := do()
+		if err == nil {
+			// Хост занят, пока вызывающий читает тело ответа.
+			result.Body = &pacedBody{ReadCloser: result.Body, release: release}
+			return result, nil
+		}
+		release()
+
+		var retryErr *RetryableError
+		if !errors.As(err, &retryErr) || attempt >= f.retry.MaxAttempts {
+			setAttempts(err, attempt)
+			return nil, err
+		}
+
+		wait := f.backoff(attempt, retryErr.RetryAfter)
+		if wait > f.retry.MaxDelay {
+			// Сервер просит подождать дольше, чем мы готовы держать воркер.
+			setAttempts(err, attempt)
+			return nil, err
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
 	}
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko)")
+}
 
+// pacedBody освобождает хост при закрытии тела ответа.
+type pacedBody struct {
+	io.ReadCloser
+	release func()
+	once    sync.Once
+}
+
+func (b *pacedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+// okOnly принимает только 200 OK: страницы с другими статусами не разбираются.
+func okOnly(status int) bool {
+	return status == http.StatusOK
+}
+
+// okOrNotModified для условных запросов дополнительно принимает 304 Not Modified.
+func okOrNotModified(status int) bool {
+	return status == http.StatusOK || status == http.StatusNotModified
+}
+
+// belowClientError принимает любые ответы, кроме ошибок 4xx и 5xx.
+func belowClientError(status int) bool {
+	return status < http.StatusBadRequest
+}
+
+func (f *HTTPFetcher) fetchOnce(
+	ctx context.Context,
+	method, url string,
+	validators domain.Validators,
+	accept func(status int) bool,
+) (*domain.FetchResult, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, &PermanentError{URL: url, Err: fmt.Errorf("не удалось создать запрос: %w", err)}
+	}
+	req.Header.Set("User-Agent", f.userAgent)
+	if validators.ETag != "" {
+		req.Header.Set("If-None-Match", validators.ETag)
+	}
+	if validators.LastModified != "" {
+		req.Header.Set("If-Modified-Since", validators.LastModified)
+	}
+
+	start := time.Now()
 	resp, err := f.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("не удалось выполнить запрос для %s: %w", url, err)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, classifyTransportError(url, err)
 	}
+	// Учитывается каждая попытка: повторы тоже нагружают сайт.
+	f.metrics.Response(req.URL.Host, resp.StatusCode, time.Since(start))
 
-	if resp.StatusCode != http.StatusOK {
+	if !accept(resp.StatusCode) {
 		_ = resp.Body.Close()
-		return nil, fmt.Errorf("неожиданный статус-код %d для %s", resp.StatusCode, url)
+		return nil, classifyStatus(url, resp)
+	}
+
+	return &domain.FetchResult{
+		URL:           url,
+		FinalURL:      resp.Request.URL.String(),
+		RedirectChain: redirectChain(resp),
+		StatusCode:    resp.StatusCode,
+		Header:        resp.Header,
+		ContentType:   resp.Header.Get("Content-Type"),
+		ContentLength: resp.ContentLength,
+		Duration:      time.Since(start),
+		Body:          resp.Body,
+	}, nil
+}
+
+// redirectChain восстанавливает цепочку редиректов: net/http сохраняет в каждом
+// следующем запросе ответ, который к нему привел.
+func redirectChain(resp *http.Response) []string {
+	var chain []string
+	for prev := resp.Request.Response; prev != nil; prev = prev.Request.Response {
+		chain = append(chain, prev.Request.URL.String())
+	}
+	slices.Reverse(chain)
+	return chain
+}
+
+// backoff считает паузу перед следующей попыткой. Retry-After от сервера важнее
+// собственного расчета, а случайный разброс не дает воркерам повторять запросы синхронно.
+func (f *HTTPFetcher) backoff(attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return retryAfter
+	}
+
+	delay := f.retry.BaseDelay << (attempt - 1)
+	if delay <= 0 || delay > f.retry.MaxDelay {
+		delay = f.retry.MaxDelay
 	}
 
-	return resp.Body, nil
+	half := delay / 2
+	return half + rand.N(half+1) //nolint:gosec // для джиттера криптостойкость не нужна
 }
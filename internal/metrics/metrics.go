@@ -0,0 +1,165 @@
+// Package metrics собирает метрики обхода в формате Prometheus.
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "crawler"
+
+// frontierTimeout — сколько ждать размер фронтира при сборе метрик.
+const frontierTimeout = 2 * time.Second
+
+// Этапы обработки, по которым считаются ошибки.
+const (
+	StageFetch   = "fetch"
+	StageParse   = "parse"
+	StageStorage = "storage"
+	StageState   = "state"
+)
+
+// Metrics — метрики краулера. Методы допускают nil-получатель и тогда ничего
+// не делают, поэтому компоненты работают одинаково с метриками и без них.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	pagesFetched    prometheus.Counter
+	bytesDownloaded prometheus.Counter
+	fetchDuration   *prometheus.HistogramVec
+	responses       *prometheus.CounterVec
+	errors          *prometheus.CounterVec
+	workers         prometheus.Gauge
+	workersBusy     prometheus.Gauge
+}
+
+// New создает метрики в собственном реестре вместе со стандартными метриками
+// Go-рантайма и процесса.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		pagesFetched: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "pages_fetched_total",
+			Help:      "Загруженные страницы и ресурсы.",
+		}),
+		bytesDownloaded: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "bytes_downloaded_total",
+			Help:      "Байты загруженных тел ответов.",
+		}),
+		fetchDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "fetch_duration_seconds",
+			Help:      "Время от отправки запроса до получения заголовков ответа.",
+			Buckets:   prometheus.ExponentialBuckets(0.05, 2, 10),
+		}, []string{"host"}),
+		responses: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_responses_total",
+			Help:      "Ответы серверов по статус-кодам.",
+		}, []string{"code"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "errors_total",
+			Help:      "Ошибки по этапам обработки: fetch, parse, storage, state.",
+		}, []string{"stage"}),
+		workers: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "workers",
+			Help:      "Число воркеров.",
+		}),
+		workersBusy: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "workers_busy",
+			Help:      "Воркеры, которые сейчас обрабатывают задачу.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.pagesFetched, m.bytesDownloaded, m.fetchDuration, m.responses, m.errors, m.workers, m.workersBusy,
+	)
+	// Ошибки всех этапов видны с нуля, а не с первой ошибки.
+	for _, stage := range []string{StageFetch, StageParse, StageStorage, StageState} {
+		m.errors.WithLabelValues(stage)
+	}
+	return m
+}
+
+// Handler возвращает обработчик /metrics.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// WatchFrontier публикует размер фронтира: он запрашивается при каждом сборе метрик.
+func (m *Metrics) WatchFrontier(size func(ctx context.Context) (int, error)) {
+	if m == nil {
+		return
+	}
+	m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "frontier_size",
+		Help:      "Задачи в очереди и в работе.",
+	}, func() float64 {
+		ctx, cancel := context.WithTimeout(context.Background(), frontierTimeout)
+		defer cancel()
+		n, err := size(ctx)
+		if err != nil {
+			return -1
+		}
+		return float64(n)
+	}))
+}
+
+// PageFetched учитывает загруженную страницу и размер ее тела.
+func (m *Metrics) PageFetched(bytes int) {
+	if m == nil {
+		return
+	}
+	m.pagesFetched.Inc()
+	m.bytesDownloaded.Add(float64(bytes))
+}
+
+// Response учитывает ответ сервера: статус-код и время до получения заголовков.
+func (m *Metrics) Response(host string, statusCode int, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	m.fetchDuration.WithLabelValues(host).Observe(duration.Seconds())
+	m.responses.WithLabelValues(strconv.Itoa(statusCode)).Inc()
+}
+
+// Error учитывает ошибку на этапе stage.
+func (m *Metrics) Error(stage string) {
+	if m == nil {
+		return
+	}
+	m.errors.WithLabelValues(stage).Inc()
+}
+
+// AddWorkers меняет число воркеров: при запуске обхода на +n, при завершении на -n.
+// В режиме server обходов несколько, и воркеры всех суммируются.
+func (m *Metrics) AddWorkers(n int) {
+	if m == nil {
+		return
+	}
+	m.workers.Add(float64(n))
+}
+
+// WorkerBusy отмечает, что воркер взял задачу. Возвращаемую функцию нужно
+// вызвать, когда задача обработана.
+func (m *Metrics) WorkerBusy() func() {
+	if m == nil {
+		return func() {}
+	}
+	m.workersBusy.Inc()
+	return m.workersBusy.Dec
+}
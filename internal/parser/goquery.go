@@ -4,10 +4,18 @@ import (
 	"bytes"
 	"fmt"
 	"net/url"
+	"strings"
+
+	"justycrawler/internal/domain"
+	"justycrawler/internal/robots"
 
 	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
 )
 
+// invisibleTags — элементы, текст которых пользователь не видит.
+const invisibleTags = "script, style, noscript, template, svg, iframe"
+
 // GoqueryParser — реализация Parser через goquery.
 type GoqueryParser struct{}
 
@@ -16,44 +24,179 @@ func New() *GoqueryParser {
 	return &GoqueryParser{}
 }
 
-// ParseLinks реализует интерфейс crawler.Parser.
-func (p *GoqueryParser) ParseLinks(baseRawURL string, htmlBody []byte) ([]string, error) {
+// ParsePage реализует интерфейс crawler.Parser.
+func (p *GoqueryParser) ParsePage(baseRawURL string, htmlBody []byte) (*domain.Page, error) {
+	baseURL, doc, err := load(baseRawURL, htmlBody)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domain.Page{
+		Title:       collapseSpaces(doc.Find("title").First().Text()),
+		Description: metaContent(doc, "description"),
+		Headings:    extractHeadings(doc),
+		Canonical:   extractCanonical(doc, baseURL),
+		Language:    extractLanguage(doc),
+		Links:       extractLinks(doc, baseURL),
+		Directives:  extractDirectives(doc),
+	}
+	// Текст извлекаем последним: для этого из документа вырезаются невидимые элементы.
+	page.Text = extractText(doc)
+
+	return page, nil
+}
+
+func load(baseRawURL string, htmlBody []byte) (*url.URL, *goquery.Document, error) {
 	baseURL, err := url.Parse(baseRawURL)
 	if err != nil {
-		return nil, fmt.Errorf("не удалось распарсить базовый URL %s: %w", baseRawURL, err)
+		return nil, nil, fmt.Errorf("не удалось распарсить базовый URL %s: %w", baseRawURL, err)
 	}
 
 	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(htmlBody))
 	if err != nil {
-		return nil, fmt.Errorf("не удалось создать goquery документ: %w", err)
+		return nil, nil, fmt.Errorf("не удалось создать goquery документ: %w", err)
 	}
+	return documentBase(doc, baseURL), doc, nil
+}
 
-	var links []string
-	seen := make(map[string]struct{})
+// documentBase учитывает <base href>: относительные ссылки страницы
+// разрешаются от него, а не от адреса запроса. Действует первый <base>.
+func documentBase(doc *goquery.Document, requestURL *url.URL) *url.URL {
+	href, ok := doc.Find("base[href]").First().Attr("href")
+	if !ok {
+		return requestURL
+	}
+	ref, err := url.Parse(strings.TrimSpace(href))
+	if err != nil {
+		return requestURL
+	}
+	base := requestURL.ResolveReference(ref)
+	if base.Scheme != "http" && base.Scheme != "https" {
+		return requestURL
+	}
+	return base
+}
 
-	doc.Find("a[href]").Each(func(_ int, s *goquery.Selection) {
-		href, _ := s.Attr("href")
-		if href == "" {
+// extractDirectives объединяет все <meta name="robots"> страницы.
+func extractDirectives(doc *goquery.Document) domain.RobotsDirectives {
+	var directives domain.RobotsDirectives
+	doc.Find("meta[name]").Each(func(_ int, s *goquery.Selection) {
+		if !strings.EqualFold(s.AttrOr("name", ""), "robots") {
 			return
 		}
+		directives = directives.Merge(robots.ParseDirectives(s.AttrOr("content", "")))
+	})
+	return directives
+}
 
-		refURL, parseErr := url.Parse(href)
-		if parseErr != nil {
-			return
+// resolve превращает href в абсолютный http(s) URL без фрагмента.
+func resolve(baseURL *url.URL, href string) (string, bool) {
+	href = strings.TrimSpace(href)
+	if href == "" {
+		return "", false
+	}
+
+	refURL, err := url.Parse(href)
+	if err != nil {
+		return "", false
+	}
+	absURL := baseURL.ResolveReference(refURL)
+
+	if absURL.Scheme != "http" && absURL.Scheme != "https" {
+		return "", false
+	}
+
+	absURL.Fragment = ""
+	return absURL.String(), true
+}
+
+func metaContent(doc *goquery.Document, name string) string {
+	var content string
+	doc.Find("meta[name]").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		if !strings.EqualFold(s.AttrOr("name", ""), name) {
+			return true
 		}
-		absURL := baseURL.ResolveReference(refURL)
+		content = strings.TrimSpace(s.AttrOr("content", ""))
+		return false
+	})
+	return content
+}
 
-		if absURL.Scheme != "http" && absURL.Scheme != "https" {
+func extractHeadings(doc *goquery.Document) []domain.Heading {
+	var headings []domain.Heading
+	doc.Find("h1, h2, h3, h4, h5, h6").Each(func(_ int, s *goquery.Selection) {
+		text := collapseSpaces(s.Text())
+		if text == "" {
 			return
 		}
+		// Имя тега — «h1»…«h6», уровень — его вторая буква.
+		level := int(goquery.NodeName(s)[1] - '0')
+		headings = append(headings, domain.Heading{Level: level, Text: text})
+	})
+	return headings
+}
 
-		absURL.Fragment = ""
-		finalURL := absURL.String()
+func extractCanonical(doc *goquery.Document, baseURL *url.URL) string {
+	var canonical string
+	doc.Find("link[rel][href]").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		if !hasToken(s.AttrOr("rel", ""), "canonical") {
+			return true
+		}
+		canonical, _ = resolve(baseURL, s.AttrOr("href", ""))
+		return false
+	})
+	return canonical
+}
+
+func extractLanguage(doc *goquery.Document) string {
+	if lang := strings.TrimSpace(doc.Find("html").AttrOr("lang", "")); lang != "" {
+		return lang
+	}
 
-		if _, ok := seen[finalURL]; !ok {
-			links = append(links, finalURL)
-			seen[finalURL] = struct{}{}
+	var lang string
+	doc.Find("meta[http-equiv]").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		if !strings.EqualFold(s.AttrOr("http-equiv", ""), "content-language") {
+			return true
 		}
+		lang = strings.TrimSpace(s.AttrOr("content", ""))
+		return false
 	})
-	return links, nil
+	return lang
+}
+
+// extractText собирает текстовые узлы через пробел: Selection.Text() склеивает
+// соседние блоки («Заголовок» + «Абзац» = «ЗаголовокАбзац»).
+func extractText(doc *goquery.Document) string {
+	body := doc.Find("body")
+	body.Find(invisibleTags).Remove()
+
+	var parts []string
+	var walk func(node *html.Node)
+	walk = func(node *html.Node) {
+		if node.Type == html.TextNode {
+			parts = append(parts, node.Data)
+		}
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	for _, node := range body.Nodes {
+		walk(node)
+	}
+
+	return collapseSpaces(strings.Join(parts, " "))
+}
+
+// hasToken проверяет, есть ли слово в списке через пробел (как в атрибуте rel).
+func hasToken(list, token string) bool {
+	for _, field := range strings.Fields(list) {
+		if strings.EqualFold(field, token) {
+			return true
+		}
+	}
+	return false
+}
+
+func collapseSpaces(s string) string {
+	return strings.Join(strings.Fields(s), " ")
 }
@@ -0,0 +1,167 @@
+package parser
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"justycrawler/internal/domain"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// cssURLPattern находит ссылки вида url(...) в CSS, с кавычками и без.
+var cssURLPattern = regexp.MustCompile(`url\(\s*(?:"([^"]*)"|'([^']*)'|([^)'"\s]*))\s*\)`)
+
+// linkCollector собирает ссылки страницы без повторов: одна и та же ссылка
+// одного вида попадает в результат один раз.
+type linkCollector struct {
+	baseURL *url.URL
+	links   []domain.Link
+	seen    map[linkKey]int // -> индекс в links
+}
+
+type linkKey struct {
+	url  string
+	kind domain.LinkKind
+}
+
+func extractLinks(doc *goquery.Document, baseURL *url.URL) []domain.Link {
+	c := &linkCollector{baseURL: baseURL, seen: make(map[linkKey]int)}
+
+	doc.Find("a[href], area[href]").Each(func(_ int, s *goquery.Selection) {
+		text := collapseSpaces(s.Text())
+		if text == "" {
+			text = collapseSpaces(s.AttrOr("alt", s.Find("img[alt]").AttrOr("alt", "")))
+		}
+		c.add(domain.LinkNavigation, s.AttrOr("href", ""), text, s.AttrOr("rel", ""))
+	})
+	// Формы с другими методами (POST — выход, удаление и т.п.) меняют состояние
+	// сайта, поэтому их адреса не записываются вовсе.
+	doc.Find("form[action]").Each(func(_ int, s *goquery.Selection) {
+		if method := strings.TrimSpace(s.AttrOr("method", "")); method == "" || strings.EqualFold(method, "get") {
+			c.add(domain.LinkForm, s.AttrOr("action", ""), "", "")
+		}
+	})
+	doc.Find("link[href]").Each(func(_ int, s *goquery.Selection) {
+		rel := s.AttrOr("rel", "")
+		kind, ok := linkElementKind(rel)
+		if !ok {
+			return
+		}
+		c.add(kind, s.AttrOr("href", ""), collapseSpaces(s.AttrOr("title", "")), rel)
+	})
+
+	doc.Find("img, source").Each(func(_ int, s *goquery.Selection) {
+		alt := collapseSpaces(s.AttrOr("alt", ""))
+		if src, ok := s.Attr("src"); ok {
+			c.add(domain.LinkResource, src, alt, "")
+		}
+		for _, src := range srcsetURLs(s.AttrOr("srcset", "")) {
+			c.add(domain.LinkResource, src, alt, "")
+		}
+	})
+	doc.Find("script[src]").Each(func(_ int, s *goquery.Selection) {
+		c.add(domain.LinkResource, s.AttrOr("src", ""), "", "")
+	})
+	doc.Find("style").Each(func(_ int, s *goquery.Selection) {
+		c.addCSS(s.Text())
+	})
+	doc.Find("[style]").Each(func(_ int, s *goquery.Selection) {
+		c.addCSS(s.AttrOr("style", ""))
+	})
+
+	doc.Find("iframe[src], frame[src], embed[src]").Each(func(_ int, s *goquery.Selection) {
+		c.add(domain.LinkEmbed, s.AttrOr("src", ""), collapseSpaces(s.AttrOr("title", "")), "")
+	})
+	doc.Find("object[data]").Each(func(_ int, s *goquery.Selection) {
+		c.add(domain.LinkEmbed, s.AttrOr("data", ""), "", "")
+	})
+
+	doc.Find("meta[http-equiv]").Each(func(_ int, s *goquery.Selection) {
+		if !strings.EqualFold(s.AttrOr("http-equiv", ""), "refresh") {
+			return
+		}
+		if target, ok := refreshURL(s.AttrOr("content", "")); ok {
+			c.add(domain.LinkRedirect, target, "", "")
+		}
+	})
+
+	return c.links
+}
+
+func (c *linkCollector) add(kind domain.LinkKind, href, text, rel string) {
+	finalURL, ok := resolve(c.baseURL, href)
+	if !ok {
+		return
+	}
+	link := domain.Link{URL: finalURL, Kind: kind, Text: text, Rel: relTokens(rel)}
+
+	key := linkKey{url: finalURL, kind: kind}
+	idx, ok := c.seen[key]
+	if !ok {
+		c.seen[key] = len(c.links)
+		c.links = append(c.links, link)
+		return
+	}
+	// Если на ту же страницу есть и обычная ссылка, по ней можно переходить.
+	if c.links[idx].NoFollow() && !link.NoFollow() {
+		c.links[idx] = link
+	}
+}
+
+func (c *linkCollector) addCSS(css string) {
+	for _, match := range cssURLPattern.FindAllStringSubmatch(css, -1) {
+		// Заполнена ровно одна из групп: в двойных, одинарных кавычках или без них.
+		c.add(domain.LinkResource, match[1]+match[2]+match[3], "", "")
+	}
+}
+
+// linkElementKind определяет вид ссылки <link> по rel. Подсказки вроде
+// preconnect указывают на хост, а не на документ, и пропускаются.
+func linkElementKind(rel string) (domain.LinkKind, bool) {
+	for _, token := range strings.Fields(strings.ToLower(rel)) {
+		switch token {
+		case "preconnect", "dns-prefetch":
+			return "", false
+		case "alternate", "canonical", "next", "prev", "home":
+			return domain.LinkNavigation, true
+		}
+	}
+	return domain.LinkResource, true
+}
+
+// srcsetURLs возвращает адреса кандидатов из srcset: «a.png 1x, b.png 2x».
+func srcsetURLs(srcset string) []string {
+	var urls []string
+	for _, candidate := range strings.Split(srcset, ",") {
+		if fields := strings.Fields(candidate); len(fields) > 0 {
+			urls = append(urls, fields[0])
+		}
+	}
+	return urls
+}
+
+// refreshURL достает адрес из content мета-редиректа: «5; url=/next».
+func refreshURL(content string) (string, bool) {
+	_, target, ok := strings.Cut(content, ";")
+	if !ok {
+		return "", false
+	}
+	target = strings.TrimSpace(target)
+	if len(target) >= 3 && strings.EqualFold(target[:3], "url") {
+		if rest, found := strings.CutPrefix(strings.TrimSpace(target[3:]), "="); found {
+			target = rest
+		}
+	}
+	target = strings.Trim(strings.TrimSpace(target), `"'`)
+	return target, target != ""
+}
+
+func relTokens(rel string) []string {
+	fields := strings.Fields(strings.ToLower(rel))
+	if len(fields) == 0 {
+		return nil
+	}
+	return fields
+}
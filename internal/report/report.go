@@ -0,0 +1,164 @@
+// Package report формирует отчет о битых ссылках в форматах console, json и csv.
+package report
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"justycrawler/internal/domain"
+)
+
+// Форматы отчета.
+const (
+	FormatConsole = "console"
+	FormatJSON    = "json"
+	FormatCSV     = "csv"
+)
+
+// Writer копит битые ссылки и записывает отчет в Render. Отчет пишется целиком
+// в конце, а не по мере обхода: так он не перемешивается с логами, а ссылки
+// одной страницы оказываются рядом.
+type Writer struct {
+	format string
+
+	mu    sync.Mutex
+	links []domain.BrokenLink
+}
+
+// New создает новый Writer для отчета в формате format.
+func New(format string) (*Writer, error) {
+	switch format {
+	case FormatConsole, FormatJSON, FormatCSV:
+	default:
+		return nil, fmt.Errorf("неизвестный формат отчета %q, допустимо: console, json, csv", format)
+	}
+	return &Writer{format: format}, nil
+}
+
+// Broken реализует интерфейс crawler.LinkReport.
+func (w *Writer) Broken(_ context.Context, link domain.BrokenLink) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.links = append(w.links, link)
+	return nil
+}
+
+// Len возвращает число битых ссылок в отчете.
+func (w *Writer) Len() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return len(w.links)
+}
+
+// Render записывает отчет в out. Ссылки упорядочены по странице-источнику и адресу.
+func (w *Writer) Render(out io.Writer) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	slices.SortFunc(w.links, func(a, b domain.BrokenLink) int {
+		if c := strings.Compare(a.Source, b.Source); c != 0 {
+			return c
+		}
+		return strings.Compare(a.URL, b.URL)
+	})
+
+	var err error
+	switch w.format {
+	case FormatJSON:
+		err = w.writeJSON(out)
+	case FormatCSV:
+		err = w.writeCSV(out)
+	default:
+		err = w.writeConsole(out)
+	}
+	if err != nil {
+		return fmt.Errorf("не удалось записать отчет о битых ссылках: %w", err)
+	}
+	return nil
+}
+
+func (w *Writer) writeJSON(out io.Writer) error {
+	links := w.links
+	if links == nil {
+		// Пустой отчет — пустой массив, а не null.
+		links = []domain.BrokenLink{}
+	}
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(links)
+}
+
+func (w *Writer) writeCSV(out io.Writer) error {
+	writer := csv.NewWriter(out)
+	header := []string{"source", "url", "kind", "anchor", "status_code", "error_class", "error", "redirect_chain"}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, link := range w.links {
+		record := []string{
+			link.Source,
+			link.URL,
+			string(link.Kind),
+			link.Anchor,
+			statusCode(link.StatusCode),
+			string(link.ErrorClass),
+			link.Error,
+			strings.Join(link.RedirectChain, " "),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeConsole пишет отчет для чтения глазами: ссылки сгруппированы по странице.
+func (w *Writer) writeConsole(out io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Битых ссылок: %d\n", len(w.links))
+
+	source := ""
+	for i, link := range w.links {
+		if i == 0 || link.Source != source {
+			source = link.Source
+			fmt.Fprintf(&b, "\n%s\n", source)
+		}
+
+		status := string(link.ErrorClass)
+		if link.StatusCode != 0 {
+			status = strconv.Itoa(link.StatusCode)
+		}
+		fmt.Fprintf(&b, "  %-8s %s", status, link.URL)
+		if link.Anchor != "" {
+			fmt.Fprintf(&b, " «%s»", link.Anchor)
+		}
+		b.WriteString("\n")
+		if len(link.RedirectChain) > 0 {
+			fmt.Fprintf(&b, "           редиректы: %s\n", strings.Join(link.RedirectChain, " → "))
+		}
+		if link.StatusCode == 0 {
+			fmt.Fprintf(&b, "           %s\n", link.Error)
+		}
+	}
+
+	_, err := io.WriteString(out, b.String())
+	return err
+}
+
+func statusCode(code int) string {
+	if code == 0 {
+		return ""
+	}
+	return strconv.Itoa(code)
+}
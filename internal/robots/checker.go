@@ -0,0 +1,141 @@
+package robots
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// unavailableTTL — как долго помнить, что robots.txt был недоступен, прежде чем попробовать снова.
+const unavailableTTL = time.Minute
+
+// Checker скачивает, кэширует по хостам и применяет robots.txt.
+type Checker struct {
+	client    *http.Client
+	userAgent string
+	cacheTTL  time.Duration
+
+	mu    sync.Mutex
+	hosts map[string]*hostEntry
+}
+
+// hostEntry — закэшированные правила одного хоста. Канал ready закрывается,
+// когда загрузка завершена, чтобы параллельные воркеры не качали файл повторно.
+type hostEntry struct {
+	ready     chan struct{}
+	rules     *Rules
+	expiresAt time.Time
+}
+
+// NewChecker создает новый Checker.
+func NewChecker(timeout time.Duration, userAgent string, cacheTTL time.Duration) *Checker {
+	return &Checker{
+		client:    &http.Client{Timeout: timeout},
+		userAgent: userAgent,
+		cacheTTL:  cacheTTL,
+		hosts:     make(map[string]*hostEntry),
+	}
+}
+
+// Allowed реализует интерфейс crawler.Robots.
+func (c *Checker) Allowed(ctx context.Context, rawURL string) (bool, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false, fmt.Errorf("не удалось распарсить URL %s: %w", rawURL, err)
+	}
+
+	rules, err := c.rulesFor(ctx, u)
+	if err != nil {
+		return false, err
+	}
+	return rules.Allowed(c.userAgent, u.RequestURI()), nil
+}
+
+// CrawlDelay реализует интерфейс crawler.Robots.
+func (c *Checker) CrawlDelay(ctx context.Context, rawURL string) (time.Duration, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось распарсить URL %s: %w", rawURL, err)
+	}
+
+	rules, err := c.rulesFor(ctx, u)
+	if err != nil {
+		return 0, err
+	}
+	return rules.CrawlDelay(c.userAgent), nil
+}
+
+// rulesFor возвращает правила хоста из кэша или загружает их. Если ctx отменен
+// раньше, чем правила готовы, возвращается ошибка контекста.
+func (c *Checker) rulesFor(ctx context.Context, u *url.URL) (*Rules, error) {
+	key := u.Scheme + "://" + u.Host
+
+	c.mu.Lock()
+	entry, ok := c.hosts[key]
+	if !ok || entry.expired() {
+		entry = &hostEntry{ready: make(chan struct{})}
+		c.hosts[key] = entry
+		// Загрузка не привязана к контексту воркера: иначе пауза или отмена обхода
+		// оставили бы в кэше запрет на весь хост до истечения unavailableTTL.
+		go c.load(context.WithoutCancel(ctx), entry, key+"/robots.txt")
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-entry.ready:
+		return entry.rules, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// load загружает robots.txt в запись кэша и сообщает ожидающим о готовности.
+func (c *Checker) load(ctx context.Context, entry *hostEntry, robotsURL string) {
+	rules, ttl := c.download(ctx, robotsURL)
+	entry.rules = rules
+	entry.expiresAt = time.Now().Add(ttl)
+	close(entry.ready)
+}
+
+// download скачивает и разбирает robots.txt. Поведение при ошибках соответствует RFC 9309:
+// 4xx — ограничений нет, 5xx и сетевые ошибки — обход запрещен.
+func (c *Checker) download(ctx context.Context, robotsURL string) (*Rules, time.Duration) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, robotsURL, nil)
+	if err != nil {
+		return DisallowAll(), unavailableTTL
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return DisallowAll(), unavailableTTL
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices:
+		data, readErr := io.ReadAll(io.LimitReader(resp.Body, maxFileSize))
+		if readErr != nil {
+			return DisallowAll(), unavailableTTL
+		}
+		return Parse(data), c.cacheTTL
+	case resp.StatusCode >= http.StatusBadRequest && resp.StatusCode < http.StatusInternalServerError:
+		return AllowAll(), c.cacheTTL
+	default:
+		return DisallowAll(), unavailableTTL
+	}
+}
+
+func (e *hostEntry) expired() bool {
+	select {
+	case <-e.ready:
+		return time.Now().After(e.expiresAt)
+	default:
+		// Загрузка еще идет — запись актуальна.
+		return false
+	}
+}
@@ -0,0 +1,51 @@
+package robots
+
+import (
+	"strings"
+
+	"justycrawler/internal/domain"
+)
+
+// ParseDirectives разбирает список директив через запятую, как в
+// <meta name="robots" content="noindex, nofollow">.
+func ParseDirectives(content string) domain.RobotsDirectives {
+	var d domain.RobotsDirectives
+	for _, token := range strings.Split(content, ",") {
+		switch strings.ToLower(strings.TrimSpace(token)) {
+		case "noindex":
+			d.NoIndex = true
+		case "nofollow":
+			d.NoFollow = true
+		case "none":
+			d.NoIndex = true
+			d.NoFollow = true
+		}
+	}
+	return d
+}
+
+// ParseXRobotsTag разбирает значения заголовка X-Robots-Tag. Значения вида
+// «googlebot: noindex» учитываются, только если адресованы нашему User-agent.
+func ParseXRobotsTag(values []string, userAgent string) domain.RobotsDirectives {
+	var d domain.RobotsDirectives
+	for _, value := range values {
+		if agent, rest, ok := strings.Cut(value, ":"); ok && isAgentToken(agent) {
+			if !strings.EqualFold(strings.TrimSpace(agent), userAgent) {
+				continue
+			}
+			value = rest
+		}
+		d = d.Merge(ParseDirectives(value))
+	}
+	return d
+}
+
+// isAgentToken отличает «googlebot: noindex» от «unavailable_after: 25 Jun 2010»:
+// имя робота — одно слово до двоеточия, не совпадающее с известной директивой.
+func isAgentToken(s string) bool {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.ContainsAny(s, ", ") {
+		return false
+	}
+	return !strings.EqualFold(s, "unavailable_after")
+}
@@ -0,0 +1,218 @@
+package robots
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxFileSize — сколько байт robots.txt мы готовы разбирать (RFC 9309 требует не меньше 500 КиБ).
+const maxFileSize = 500 * 1024
+
+// Rules — разобранный файл robots.txt.
+type Rules struct {
+	groups   []group
+	sitemaps []string
+}
+
+// group — набор правил для одного или нескольких User-agent.
+type group struct {
+	agents     []string
+	rules      []rule
+	crawlDelay time.Duration
+}
+
+type rule struct {
+	allow   bool
+	pattern string
+}
+
+// AllowAll возвращает правила, разрешающие обход всего сайта.
+func AllowAll() *Rules {
+	return &Rules{}
+}
+
+// DisallowAll возвращает правила, запрещающие обход всего сайта.
+func DisallowAll() *Rules {
+	return &Rules{groups: []group{{agents: []string{"*"}, rules: []rule{{allow: false, pattern: "/"}}}}}
+}
+
+// Parse разбирает содержимое robots.txt. Неизвестные директивы и мусорные строки игнорируются.
+func Parse(data []byte) *Rules {
+	if len(data) > maxFileSize {
+		data = data[:maxFileSize]
+	}
+
+	var (
+		groups   []group
+		sitemaps []string
+		current  *group
+		// Подряд идущие строки User-agent относятся к одной группе,
+		// а User-agent после правил начинает новую.
+		agentsOpen bool
+	)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxFileSize)
+	for scanner.Scan() {
+		key, value, ok := splitLine(scanner.Text())
+		if !ok {
+			continue
+		}
+
+		switch key {
+		case "user-agent":
+			if !agentsOpen {
+				groups = append(groups, group{})
+				current = &groups[len(groups)-1]
+				agentsOpen = true
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+		case "allow", "disallow":
+			agentsOpen = false
+			if current == nil || value == "" {
+				// Пустой Disallow означает «разрешено всё» и правил не добавляет.
+				continue
+			}
+			current.rules = append(current.rules, rule{allow: key == "allow", pattern: value})
+		case "sitemap":
+			// Sitemap не относится к группам User-agent и группу не прерывает.
+			if value != "" {
+				sitemaps = append(sitemaps, value)
+			}
+		case "crawl-delay":
+			agentsOpen = false
+			if current == nil {
+				continue
+			}
+			if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+				current.crawlDelay = time.Duration(seconds * float64(time.Second))
+			}
+		}
+	}
+
+	return &Rules{groups: groups, sitemaps: sitemaps}
+}
+
+// Sitemaps возвращает адреса из строк Sitemap в порядке их появления в файле.
+func (r *Rules) Sitemaps() []string {
+	return r.sitemaps
+}
+
+// Allowed сообщает, разрешен ли путь (вместе с query) для указанного User-agent.
+// Побеждает самое длинное совпавшее правило, при равной длине — Allow.
+func (r *Rules) Allowed(userAgent, path string) bool {
+	if path == "" {
+		path = "/"
+	}
+	if path == "/robots.txt" {
+		return true
+	}
+
+	allowed := true
+	matchedLen := -1
+	for _, g := range r.groupsFor(userAgent) {
+		for _, rl := range g.rules {
+			if !matchPattern(rl.pattern, path) {
+				continue
+			}
+			length := len(rl.pattern)
+			if length > matchedLen || (length == matchedLen && rl.allow) {
+				matchedLen = length
+				allowed = rl.allow
+			}
+		}
+	}
+	return allowed
+}
+
+// CrawlDelay возвращает Crawl-delay для указанного User-agent или 0, если он не задан.
+func (r *Rules) CrawlDelay(userAgent string) time.Duration {
+	var delay time.Duration
+	for _, g := range r.groupsFor(userAgent) {
+		delay = max(delay, g.crawlDelay)
+	}
+	return delay
+}
+
+// groupsFor выбирает группы с самым специфичным совпадением User-agent,
+// а если таких нет — группы для «*».
+func (r *Rules) groupsFor(userAgent string) []group {
+	userAgent = strings.ToLower(userAgent)
+
+	var (
+		best     []group
+		bestLen  int
+		wildcard []group
+	)
+	for _, g := range r.groups {
+		for _, agent := range g.agents {
+			if agent == "*" {
+				wildcard = append(wildcard, g)
+				break
+			}
+			if !strings.HasPrefix(userAgent, agent) {
+				continue
+			}
+			switch {
+			case len(agent) > bestLen:
+				best = []group{g}
+				bestLen = len(agent)
+			case len(agent) == bestLen:
+				best = append(best, g)
+			}
+			break
+		}
+	}
+
+	if len(best) > 0 {
+		return best
+	}
+	return wildcard
+}
+
+// splitLine отрезает комментарий и разбивает строку на директиву и значение.
+func splitLine(line string) (string, string, bool) {
+	if idx := strings.IndexByte(line, '#'); idx >= 0 {
+		line = line[:idx]
+	}
+	key, value, ok := strings.Cut(line, ":")
+	if !ok {
+		return "", "", false
+	}
+	return strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value), true
+}
+
+// matchPattern сопоставляет путь с шаблоном robots.txt, где «*» — любая последовательность
+// символов, а «$» в конце привязывает шаблон к концу пути.
+func matchPattern(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	if anchored {
+		pattern = strings.TrimSuffix(pattern, "$")
+	}
+
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	rest := path[len(parts[0]):]
+
+	for i, part := range parts[1:] {
+		isLast := i == len(parts)-2
+		if isLast && anchored {
+			return strings.HasSuffix(rest, part)
+		}
+		idx := strings.Index(rest, part)
+		if idx < 0 {
+			return false
+		}
+		rest = rest[idx+len(part):]
+	}
+
+	if anchored {
+		return rest == ""
+	}
+	return true
+}
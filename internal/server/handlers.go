@@ -0,0 +1,181 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"justycrawler/internal/domain"
+)
+
+const (
+	defaultResultsLimit = 100
+	maxResultsLimit     = 1000
+	// maxRequestBody — предел тела запроса на создание задания.
+	maxRequestBody = 1 << 20
+)
+
+// submitRequest — тело POST /jobs: настройки задания в том же виде, что и в config.yaml.
+type submitRequest struct {
+	Config map[string]any `json:"config"`
+}
+
+// resultView — результат обхода URL в ответе API.
+type resultView struct {
+	URL           string             `json:"url"`
+	Status        domain.CrawlStatus `json:"status"`
+	Depth         int                `json:"depth"`
+	FoundOn       string             `json:"found_on,omitempty"`
+	StatusCode    int                `json:"status_code,omitempty"`
+	FinalURL      string             `json:"final_url,omitempty"`
+	ContentType   string             `json:"content_type,omitempty"`
+	ContentLength int64              `json:"content_length,omitempty"`
+	FetchDuration float64            `json:"fetch_duration_seconds,omitempty"`
+	SkipReason    string             `json:"skip_reason,omitempty"`
+	Canonical     string             `json:"canonical,omitempty"`
+	FoundLinks    int                `json:"found_links"`
+	Error         *domain.Failure    `json:"error,omitempty"`
+	RedirectChain []string           `json:"redirect_chain,omitempty"`
+}
+
+// Handler возвращает HTTP-обработчик API заданий:
+//
+//	POST /jobs                  — создать и запустить задание;
+//	GET  /jobs                  — список заданий;
+//	GET  /jobs/{id}             — состояние задания;
+//	POST /jobs/{id}/pause       — поставить на паузу;
+//	POST /jobs/{id}/resume      — продолжить;
+//	POST /jobs/{id}/cancel      — отменить;
+//	GET  /jobs/{id}/results     — результаты (?status=&skip=&limit=).
+func (m *Manager) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /jobs", m.handleSubmit)
+	mux.HandleFunc("GET /jobs", m.handleList)
+	mux.HandleFunc("GET /jobs/{id}", m.handleGet)
+	mux.HandleFunc("POST /jobs/{id}/pause", m.handleAction(m.Pause))
+	mux.HandleFunc("POST /jobs/{id}/resume", m.handleAction(m.Resume))
+	mux.HandleFunc("POST /jobs/{id}/cancel", m.handleAction(m.Cancel))
+	mux.HandleFunc("GET /jobs/{id}/results", m.handleResults)
+	return mux
+}
+
+func (m *Manager) handleSubmit(w http.ResponseWriter, r *http.Request) {
+	var req submitRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBody))
+	if err := decoder.Decode(&req); err != nil {
+		m.writeError(w, fmt.Errorf("%w: не удалось разобрать тело запроса: %w", ErrBadRequest, err))
+		return
+	}
+
+	view, err := m.Submit(req.Config)
+	if err != nil {
+		m.writeError(w, err)
+		return
+	}
+	m.writeJSON(w, http.StatusCreated, view)
+}
+
+func (m *Manager) handleList(w http.ResponseWriter, _ *http.Request) {
+	m.writeJSON(w, http.StatusOK, m.List())
+}
+
+func (m *Manager) handleGet(w http.ResponseWriter, r *http.Request) {
+	view, err := m.Get(r.PathValue("id"))
+	if err != nil {
+		m.writeError(w, err)
+		return
+	}
+	m.writeJSON(w, http.StatusOK, view)
+}
+
+// handleAction — обработчик действия над заданием: pause, resume или cancel.
+func (m *Manager) handleAction(action func(id string) (JobView, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		view, err := action(r.PathValue("id"))
+		if err != nil {
+			m.writeError(w, err)
+			return
+		}
+		m.writeJSON(w, http.StatusOK, view)
+	}
+}
+
+func (m *Manager) handleResults(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	skip, err := queryInt(query.Get("skip"), 0)
+	if err != nil {
+		m.writeError(w, err)
+		return
+	}
+	limit, err := queryInt(query.Get("limit"), defaultResultsLimit)
+	if err != nil {
+		m.writeError(w, err)
+		return
+	}
+	limit = min(max(limit, 1), maxResultsLimit)
+
+	results, err := m.Results(r.Context(), r.PathValue("id"), query.Get("status"), skip, limit)
+	if err != nil {
+		m.writeError(w, err)
+		return
+	}
+
+	views := make([]resultView, 0, len(results))
+	for _, res := range results {
+		views = append(views, resultView{
+			URL:           res.URL,
+			Status:        res.Status,
+			Depth:         res.Depth,
+			FoundOn:       res.FoundOn,
+			StatusCode:    res.StatusCode,
+			FinalURL:      res.FinalURL,
+			ContentType:   res.ContentType,
+			ContentLength: res.ContentLength,
+			FetchDuration: res.FetchDuration.Seconds(),
+			SkipReason:    res.SkipReason,
+			Canonical:     res.Canonical,
+			FoundLinks:    len(res.FoundLinks),
+			Error:         res.Error,
+			RedirectChain: res.RedirectChain,
+		})
+	}
+	m.writeJSON(w, http.StatusOK, views)
+}
+
+func (m *Manager) writeJSON(w http.ResponseWriter, code int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		m.logger.Warn("Не удалось отправить ответ API", slog.Any("error", err))
+	}
+}
+
+// writeError отвечает ошибкой с кодом по ее виду.
+func (m *Manager) writeError(w http.ResponseWriter, err error) {
+	code := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, ErrJobNotFound):
+		code = http.StatusNotFound
+	case errors.Is(err, ErrJobState):
+		code = http.StatusConflict
+	case errors.Is(err, ErrBadRequest):
+		code = http.StatusBadRequest
+	default:
+		m.logger.Error("Ошибка обработки запроса API", slog.Any("error", err))
+	}
+	m.writeJSON(w, code, map[string]string{"error": err.Error()})
+}
+
+func queryInt(value string, fallback int64) (int64, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%w: ожидается неотрицательное целое, получено %q", ErrBadRequest, value)
+	}
+	return n, nil
+}
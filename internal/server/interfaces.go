@@ -0,0 +1,39 @@
+package server
+
+import (
+	"context"
+
+	"justycrawler/internal/app/crawler"
+	"justycrawler/internal/config"
+	"justycrawler/internal/domain"
+)
+
+// Environment — хранилища одного задания: стейт и фронтир с ключами задания.
+// Живет от запуска задания до его завершения, в том числе пока задание на паузе.
+//
+//go:generate mockery --name Environment --output ../../mocks --outpkg mocks
+type Environment interface {
+	// Crawler создает краулер задания; resume — продолжить с задач, оставшихся во фронтире.
+	Crawler(resume bool) (*crawler.Crawler, error)
+	// FrontierLen возвращает число задач задания в очереди и в работе.
+	FrontierLen(ctx context.Context) (int, error)
+	// Clear удаляет фронтир задания и, если keepState не задан, его стейт.
+	Clear(ctx context.Context, keepState bool) error
+	Close() error
+}
+
+// EnvironmentFactory создает окружение задания с настройками cfg.
+type EnvironmentFactory func(ctx context.Context, cfg *config.Config) (Environment, error)
+
+// Results читает сохраненные результаты заданий и хранит записи о них.
+//
+//go:generate mockery --name Results --output ../../mocks --outpkg mocks
+type Results interface {
+	Find(ctx context.Context, jobID, status string, skip, limit int64) ([]domain.CrawledData, error)
+	// SaveRun сохраняет запись о задании; при первом сохранении заполняет run.ID.
+	SaveRun(ctx context.Context, run *domain.Run) error
+	// LastRun возвращает последнюю запись о задании jobID; false — записей нет.
+	LastRun(ctx context.Context, jobID string) (domain.Run, bool, error)
+	// PausedRuns возвращает записи о заданиях на паузе, новые первыми.
+	PausedRuns(ctx context.Context) ([]domain.Run, error)
+}
@@ -0,0 +1,218 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"justycrawler/internal/app/crawler"
+	"justycrawler/internal/config"
+	"justycrawler/internal/domain"
+)
+
+// JobStatus — состояние задания.
+type JobStatus string
+
+const (
+	JobRunning   JobStatus = "running"
+	JobPaused    JobStatus = "paused"
+	JobCompleted JobStatus = "completed"
+	JobCancelled JobStatus = "cancelled"
+	JobFailed    JobStatus = "failed"
+)
+
+// finished сообщает, что задание завершено и продолжить его нельзя.
+func (s JobStatus) finished() bool {
+	return s == JobCompleted || s == JobCancelled || s == JobFailed
+}
+
+// storeTimeout — сколько ждать удаления ключей и сохранения записи о задании.
+const storeTimeout = 10 * time.Second
+
+// Stats — итоги задания по всем запускам с учетом пауз.
+type Stats struct {
+	Pages      int64   `json:"pages"`
+	Bytes      int64   `json:"bytes"`
+	Duration   float64 `json:"duration_seconds"`
+	StopReason string  `json:"stop_reason,omitempty"` // Лимит, остановивший обход.
+}
+
+// JobView — состояние задания для API.
+type JobView struct {
+	ID         string         `json:"id"`
+	Status     JobStatus      `json:"status"`
+	StartURLs  []string       `json:"start_urls"`
+	Config     map[string]any `json:"config"` // Переопределения из запроса.
+	Stats      Stats          `json:"stats"`
+	Error      string         `json:"error,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
+	FinishedAt *time.Time     `json:"finished_at,omitempty"`
+}
+
+// job — задание: обход с собственными стейтом и фронтиром. Пауза останавливает
+// текущий краулер, оставляя фронтир в хранилище; возобновление запускает новый
+// краулер в режиме resume на тех же ключах.
+type job struct {
+	id        string
+	cfg       *config.Config
+	overrides map[string]any
+	env       Environment
+	results   Results
+	logger    *slog.Logger
+	createdAt time.Time
+	record    *domain.Run // Запись о задании в хранилище, обновляется при смене состояния.
+
+	mu         sync.Mutex
+	status     JobStatus
+	err        string
+	finishedAt time.Time
+	previous   crawler.Result   // Сумма итогов завершенных запусков.
+	current    *crawler.Crawler // nil, если задание не выполняется.
+	cancel     context.CancelFunc
+	stopped    chan struct{} // Закрывается, когда текущий запуск завершился.
+	stopAs     JobStatus     // Состояние, которое запросили pause или cancel.
+}
+
+// start запускает обход задания. Вызывается под j.mu.
+func (j *job) start(ctx context.Context, resume bool) error {
+	cr, err := j.env.Crawler(resume)
+	if err != nil {
+		return err
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	stopped := make(chan struct{})
+	j.current, j.cancel, j.stopped = cr, cancel, stopped
+	j.status = JobRunning
+	j.saveRun()
+
+	go j.run(runCtx, cr, stopped)
+	return nil
+}
+
+func (j *job) run(ctx context.Context, cr *crawler.Crawler, stopped chan struct{}) {
+	defer close(stopped)
+
+	result, err := cr.Run(ctx, j.cfg.StartURLs)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	// Проверяем до j.cancel: отмена контекста запуска без pause и cancel означает остановку сервера.
+	interrupted := ctx.Err() != nil
+	j.cancel()
+	j.previous = addResult(j.previous, result)
+	j.current = nil
+
+	switch {
+	case j.stopAs != "":
+		j.status = j.stopAs
+	case err != nil && !errors.Is(err, context.Canceled):
+		j.status = JobFailed
+		j.err = err.Error()
+	case interrupted:
+		j.status = JobPaused
+	default:
+		j.status = JobCompleted
+	}
+	j.stopAs = ""
+	j.logger.Info("Запуск задания завершен", slog.String("status", string(j.status)),
+		slog.Int64("pages", result.Pages), slog.Any("error", err))
+
+	if j.status != JobPaused {
+		j.finish()
+	}
+	j.saveRun()
+}
+
+// stop останавливает текущий запуск и ждет его завершения. Задание переходит
+// в состояние status. Вызывается под j.mu, на время ожидания мьютекс отпускается.
+func (j *job) stop(status JobStatus) {
+	j.stopAs = status
+	j.cancel()
+	stopped := j.stopped
+
+	j.mu.Unlock()
+	<-stopped
+	j.mu.Lock()
+}
+
+// finish освобождает ключи завершенного задания. Результаты остаются в хранилище.
+// С revisit стейт хранит время посещений, и задание с тем же job_id обходит снова
+// только устаревшие URL, поэтому удаляется лишь фронтир. Вызывается под j.mu.
+func (j *job) finish() {
+	j.finishedAt = time.Now()
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	if err := j.env.Clear(ctx, j.cfg.Revisit.Enabled()); err != nil {
+		j.logger.Error("Не удалось удалить стейт и фронтир задания", slog.Any("error", err))
+	}
+	if err := j.env.Close(); err != nil {
+		j.logger.Error("Не удалось закрыть хранилища задания", slog.Any("error", err))
+	}
+}
+
+// saveRun сохраняет запись о задании с текущим состоянием и статистикой.
+// Ошибка только логируется: задание продолжает работать и без записи. Вызывается под j.mu.
+func (j *job) saveRun() {
+	result := j.result()
+	j.record.Status = domain.RunStatus(j.status)
+	j.record.Error = j.err
+	j.record.StartedAt = j.createdAt
+	j.record.FinishedAt = j.finishedAt
+	j.record.Stats = domain.RunStats{
+		Pages:      result.Pages,
+		Bytes:      result.Bytes,
+		Duration:   result.Duration,
+		StopReason: result.StopReason,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	if err := j.results.SaveRun(ctx, j.record); err != nil {
+		j.logger.Error("Не удалось сохранить запись о задании", slog.Any("error", err))
+	}
+}
+
+// view возвращает состояние задания. Вызывается под j.mu.
+func (j *job) view() JobView {
+	result := j.result()
+	v := JobView{
+		ID:        j.id,
+		Status:    j.status,
+		StartURLs: j.cfg.StartURLs,
+		Config:    j.overrides,
+		Stats: Stats{
+			Pages:      result.Pages,
+			Bytes:      result.Bytes,
+			Duration:   result.Duration.Seconds(),
+			StopReason: result.StopReason,
+		},
+		Error:     j.err,
+		CreatedAt: j.createdAt,
+	}
+	if !j.finishedAt.IsZero() {
+		finishedAt := j.finishedAt
+		v.FinishedAt = &finishedAt
+	}
+	return v
+}
+
+// result возвращает итоги всех запусков задания, включая текущий. Вызывается под j.mu.
+func (j *job) result() crawler.Result {
+	if j.current == nil {
+		return j.previous
+	}
+	return addResult(j.previous, j.current.Progress())
+}
+
+func addResult(total, r crawler.Result) crawler.Result {
+	total.Pages += r.Pages
+	total.Bytes += r.Bytes
+	total.Duration += r.Duration
+	total.StopReason = r.StopReason
+	return total
+}
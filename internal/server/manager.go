@@ -0,0 +1,403 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"sort"
+	"sync"
+	"time"
+
+	"justycrawler/internal/app/crawler"
+	"justycrawler/internal/config"
+	"justycrawler/internal/domain"
+)
+
+var (
+	// ErrJobNotFound — задания с таким идентификатором нет.
+	ErrJobNotFound = errors.New("задание не найдено")
+	// ErrJobState — действие недоступно в текущем состоянии задания.
+	ErrJobState = errors.New("недопустимое состояние задания")
+	// ErrBadRequest — некорректный запрос или настройки задания.
+	ErrBadRequest = errors.New("некорректный запрос")
+)
+
+// Manager запускает задания обхода и управляет ими. Задания живут в памяти
+// процесса, их результаты — в общем хранилище с отметкой job_id. Завершенные
+// задания забываются через server.job_ttl, их результаты остаются в хранилище.
+type Manager struct {
+	ctx     context.Context
+	cfg     *config.Config
+	newEnv  EnvironmentFactory
+	results Results
+	logger  *slog.Logger
+
+	mu      sync.RWMutex
+	jobs    map[string]*job
+	pending map[string]bool // Задания, которые сейчас создаются.
+}
+
+// NewManager создает менеджер заданий. Задания наследуют общий конфиг cfg;
+// отмена ctx ставит выполняющиеся задания на паузу.
+func NewManager(
+	ctx context.Context,
+	cfg *config.Config,
+	newEnv EnvironmentFactory,
+	results Results,
+	logger *slog.Logger,
+) *Manager {
+	return &Manager{
+		ctx:     ctx,
+		cfg:     cfg,
+		newEnv:  newEnv,
+		results: results,
+		logger:  logger,
+		jobs:    make(map[string]*job),
+		pending: make(map[string]bool),
+	}
+}
+
+// Submit создает задание с переопределенными настройками overrides и запускает его.
+// Задание с job_id завершенного задания заменяет его и продолжает писать в те же результаты.
+func (m *Manager) Submit(overrides map[string]any) (JobView, error) {
+	jobCfg, err := m.cfg.ForJob(overrides)
+	if err != nil {
+		return JobView{}, fmt.Errorf("%w: %w", ErrBadRequest, err)
+	}
+	id := jobCfg.JobID
+
+	// Место занимаем до подготовки хранилищ, чтобы два задания с одним job_id
+	// не начали работать с одними ключами.
+	m.prune()
+	if err := m.reserve(id); err != nil {
+		return JobView{}, err
+	}
+	defer m.release(id)
+
+	env, err := m.newEnv(m.ctx, jobCfg)
+	if err != nil {
+		return JobView{}, fmt.Errorf("не удалось подготовить хранилища задания: %w", err)
+	}
+
+	j := &job{
+		id:        id,
+		cfg:       jobCfg,
+		overrides: overrides,
+		env:       env,
+		results:   m.results,
+		logger:    m.logger.With(slog.String("job_id", id)),
+		createdAt: time.Now(),
+		record: &domain.Run{
+			JobID:     id,
+			Mode:      jobCfg.Mode,
+			WorkerID:  jobCfg.WorkerID,
+			StartURLs: jobCfg.StartURLs,
+			Config:    jobCfg.Snapshot(),
+			Overrides: overrides,
+		},
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err := j.start(m.ctx, false); err != nil {
+		j.finish()
+		return JobView{}, fmt.Errorf("%w: %w", ErrBadRequest, err)
+	}
+
+	m.mu.Lock()
+	m.jobs[id] = j
+	m.mu.Unlock()
+
+	j.logger.Info("Задание запущено", slog.Any("start_urls", jobCfg.StartURLs))
+	return j.view(), nil
+}
+
+// List возвращает все задания, новые первыми.
+func (m *Manager) List() []JobView {
+	m.prune()
+
+	m.mu.RLock()
+	jobs := make([]*job, 0, len(m.jobs))
+	for _, j := range m.jobs {
+		jobs = append(jobs, j)
+	}
+	m.mu.RUnlock()
+
+	views := make([]JobView, 0, len(jobs))
+	for _, j := range jobs {
+		j.mu.Lock()
+		views = append(views, j.view())
+		j.mu.Unlock()
+	}
+	sort.Slice(views, func(a, b int) bool {
+		return views[a].CreatedAt.After(views[b].CreatedAt)
+	})
+	return views
+}
+
+// Get возвращает состояние задания.
+func (m *Manager) Get(id string) (JobView, error) {
+	j, err := m.job(id)
+	if err != nil {
+		return JobView{}, err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.view(), nil
+}
+
+// Pause останавливает задание, оставляя очередь во фронтире.
+func (m *Manager) Pause(id string) (JobView, error) {
+	j, err := m.job(id)
+	if err != nil {
+		return JobView{}, err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.status != JobRunning {
+		return JobView{}, fmt.Errorf("%w: задание в состоянии %s нельзя поставить на паузу", ErrJobState, j.status)
+	}
+	j.stop(JobPaused)
+	j.logger.Info("Задание поставлено на паузу")
+	return j.view(), nil
+}
+
+// Resume продолжает задание с задач, оставшихся во фронтире.
+func (m *Manager) Resume(id string) (JobView, error) {
+	j, err := m.job(id)
+	if err != nil {
+		return JobView{}, err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.status != JobPaused {
+		return JobView{}, fmt.Errorf("%w: продолжить можно только задание на паузе, а не %s", ErrJobState, j.status)
+	}
+	if err := m.ctx.Err(); err != nil {
+		return JobView{}, fmt.Errorf("%w: сервер останавливается", ErrJobState)
+	}
+	if err := j.start(m.ctx, true); err != nil {
+		return JobView{}, fmt.Errorf("не удалось продолжить задание: %w", err)
+	}
+	j.logger.Info("Задание продолжено")
+	return j.view(), nil
+}
+
+// Cancel останавливает задание без возможности продолжить и удаляет его фронтир,
+// а без revisit — и стейт.
+func (m *Manager) Cancel(id string) (JobView, error) {
+	j, err := m.job(id)
+	if err != nil {
+		return JobView{}, err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	switch j.status {
+	case JobRunning:
+		j.stop(JobCancelled)
+	case JobPaused:
+		j.status = JobCancelled
+		j.finish()
+		j.saveRun()
+	case JobCompleted, JobCancelled, JobFailed:
+		return JobView{}, fmt.Errorf("%w: задание уже завершено (%s)", ErrJobState, j.status)
+	}
+	j.logger.Info("Задание отменено")
+	return j.view(), nil
+}
+
+// Results возвращает сохраненные результаты задания. Пустой status — все результаты.
+func (m *Manager) Results(ctx context.Context, id, status string, skip, limit int64) ([]domain.CrawledData, error) {
+	if _, err := m.job(id); err != nil {
+		// Завершенное задание могло быть забыто по server.job_ttl или до перезапуска
+		// сервера, а его результаты остались в хранилище.
+		_, found, runErr := m.results.LastRun(ctx, id)
+		if runErr != nil {
+			return nil, runErr
+		}
+		if !found {
+			return nil, err
+		}
+	}
+	return m.results.Find(ctx, id, status, skip, limit)
+}
+
+// Restore загружает задания, оставшиеся на паузе при прошлой остановке сервера.
+// Их фронтир сохранен в Redis, и задание можно продолжить через resume. Задание,
+// которое не удалось восстановить, отмечается в хранилище как failed.
+func (m *Manager) Restore(ctx context.Context) error {
+	runs, err := m.results.PausedRuns(ctx)
+	if err != nil {
+		return fmt.Errorf("не удалось загрузить задания на паузе: %w", err)
+	}
+
+	for _, run := range runs {
+		// Записи идут от новых к старым: более старая запись того же задания устарела.
+		if _, err := m.job(run.JobID); err == nil {
+			continue
+		}
+		if err := m.restore(ctx, &run); err != nil {
+			m.logger.Error("Не удалось восстановить задание", slog.String("job_id", run.JobID), slog.Any("error", err))
+			run.Status = domain.RunFailed
+			run.Error = err.Error()
+			run.FinishedAt = time.Now()
+			if saveErr := m.results.SaveRun(ctx, &run); saveErr != nil {
+				m.logger.Error("Не удалось сохранить запись о задании", slog.Any("error", saveErr))
+			}
+		}
+	}
+	return nil
+}
+
+// FrontierSize возвращает число задач в очереди и в работе у всех заданий,
+// которые выполняются или стоят на паузе.
+func (m *Manager) FrontierSize(ctx context.Context) (int, error) {
+	m.mu.RLock()
+	envs := make([]Environment, 0, len(m.jobs))
+	for _, j := range m.jobs {
+		j.mu.Lock()
+		if !j.status.finished() {
+			envs = append(envs, j.env)
+		}
+		j.mu.Unlock()
+	}
+	m.mu.RUnlock()
+
+	total := 0
+	for _, env := range envs {
+		n, err := env.FrontierLen(ctx)
+		if err != nil {
+			return 0, err
+		}
+		total += n
+	}
+	return total, nil
+}
+
+// Shutdown ждет остановки выполняющихся заданий после отмены контекста менеджера
+// и закрывает хранилища заданий, оставшихся на паузе.
+func (m *Manager) Shutdown() {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, j := range m.jobs {
+		j.mu.Lock()
+		if j.status == JobRunning {
+			j.stop(JobPaused)
+		}
+		if j.status == JobPaused {
+			if err := j.env.Close(); err != nil {
+				j.logger.Error("Не удалось закрыть хранилища задания", slog.Any("error", err))
+			}
+		}
+		j.mu.Unlock()
+	}
+}
+
+// reserve занимает идентификатор id для нового задания. Занять можно только
+// идентификатор, у которого нет задания или оно уже завершено.
+func (m *Manager) reserve(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.pending[id] {
+		return fmt.Errorf("%w: задание %s уже создается", ErrJobState, id)
+	}
+	if j, ok := m.jobs[id]; ok {
+		j.mu.Lock()
+		status := j.status
+		j.mu.Unlock()
+		if status == JobRunning || status == JobPaused {
+			return fmt.Errorf("%w: задание %s еще не завершено (%s)", ErrJobState, id, status)
+		}
+	}
+	m.pending[id] = true
+	return nil
+}
+
+// prune удаляет из памяти задания, завершенные раньше, чем server.job_ttl назад.
+// Стейт и фронтир таких заданий уже освобождены в finish.
+func (m *Manager) prune() {
+	ttl := m.cfg.Server.JobTTL
+	if ttl <= 0 {
+		return
+	}
+	cutoff := time.Now().Add(-ttl)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, j := range m.jobs {
+		j.mu.Lock()
+		expired := j.status.finished() && j.finishedAt.Before(cutoff)
+		j.mu.Unlock()
+		if expired {
+			delete(m.jobs, id)
+			j.logger.Debug("Завершенное задание удалено из памяти")
+		}
+	}
+}
+
+// restore создает задание на паузе по записи run, сохраненной перед остановкой сервера.
+func (m *Manager) restore(ctx context.Context, run *domain.Run) error {
+	// Сгенерированный job_id в переопределения не попадает, а ключи задания зависят от него.
+	overrides := make(map[string]any, len(run.Overrides)+1)
+	maps.Copy(overrides, run.Overrides)
+	overrides["job_id"] = run.JobID
+
+	jobCfg, err := m.cfg.ForJob(overrides)
+	if err != nil {
+		return err
+	}
+	env, err := m.newEnv(ctx, jobCfg)
+	if err != nil {
+		return fmt.Errorf("не удалось подготовить хранилища задания: %w", err)
+	}
+
+	j := &job{
+		id:        run.JobID,
+		cfg:       jobCfg,
+		overrides: run.Overrides,
+		env:       env,
+		results:   m.results,
+		logger:    m.logger.With(slog.String("job_id", run.JobID)),
+		createdAt: run.StartedAt,
+		record:    run,
+		status:    JobPaused,
+		previous: crawler.Result{
+			Pages:      run.Stats.Pages,
+			Bytes:      run.Stats.Bytes,
+			Duration:   run.Stats.Duration,
+			StopReason: run.Stats.StopReason,
+		},
+	}
+
+	m.mu.Lock()
+	m.jobs[j.id] = j
+	m.mu.Unlock()
+
+	j.logger.Info("Задание на паузе восстановлено после перезапуска сервера")
+	return nil
+}
+
+func (m *Manager) release(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.pending, id)
+}
+
+func (m *Manager) job(id string) (*job, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	j, ok := m.jobs[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrJobNotFound, id)
+	}
+	return j, nil
+}
@@ -0,0 +1,350 @@
+package server_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"justycrawler/internal/app/crawler"
+	"justycrawler/internal/config"
+	"justycrawler/internal/domain"
+	"justycrawler/internal/fetcher"
+	"justycrawler/internal/parser"
+	"justycrawler/internal/server"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// visitState — стейт в памяти со временем посещений, как RedisState.
+type visitState struct {
+	mu      sync.Mutex
+	visited map[string]time.Time
+}
+
+func (s *visitState) Add(_ context.Context, url string, staleBefore time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if last, ok := s.visited[url]; ok && !last.Before(staleBefore) {
+		return false, nil
+	}
+	s.visited[url] = time.Now()
+	return true, nil
+}
+
+func (s *visitState) Clear(context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clear(s.visited)
+	return nil
+}
+
+func (s *visitState) Close() error { return nil }
+
+type discardStorage struct{}
+
+func (discardStorage) Save(context.Context, domain.CrawledData) error { return nil }
+func (discardStorage) Close(context.Context) error                    { return nil }
+
+// memoryResults хранит записи о заданиях в памяти; результаты обхода не хранит.
+type memoryResults struct {
+	mu   sync.Mutex
+	runs map[string]domain.Run
+	seq  int
+}
+
+func (r *memoryResults) Find(context.Context, string, string, int64, int64) ([]domain.CrawledData, error) {
+	return nil, nil
+}
+
+func (r *memoryResults) SaveRun(_ context.Context, run *domain.Run) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if run.ID == "" {
+		r.seq++
+		run.ID = strconv.Itoa(r.seq)
+	}
+	r.runs[run.ID] = *run
+	return nil
+}
+
+func (r *memoryResults) LastRun(_ context.Context, jobID string) (domain.Run, bool, error) {
+	runs := r.sorted(func(run domain.Run) bool { return run.JobID == jobID })
+	if len(runs) == 0 {
+		return domain.Run{}, false, nil
+	}
+	return runs[0], true, nil
+}
+
+func (r *memoryResults) PausedRuns(context.Context) ([]domain.Run, error) {
+	return r.sorted(func(run domain.Run) bool { return run.Status == domain.RunPaused }), nil
+}
+
+// sorted возвращает подходящие записи, новые первыми.
+func (r *memoryResults) sorted(match func(domain.Run) bool) []domain.Run {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var runs []domain.Run
+	for _, run := range r.runs {
+		if match(run) {
+			runs = append(runs, run)
+		}
+	}
+	sort.Slice(runs, func(a, b int) bool { return runs[a].StartedAt.After(runs[b].StartedAt) })
+	return runs
+}
+
+// testEnvironment — окружение задания в памяти. Стейт и фронтир берутся по job_id
+// из testStores и переживают задание и перезапуск менеджера, как ключи в Redis.
+type testEnvironment struct {
+	cfg      *config.Config
+	state    *visitState
+	frontier crawler.Frontier
+}
+
+func (e *testEnvironment) Crawler(resume bool) (*crawler.Crawler, error) {
+	var revisit crawler.RevisitFunc
+	if e.cfg.Revisit.Enabled() {
+		revisit = func(domain.Task) time.Duration { return e.cfg.Revisit.Interval }
+	}
+	return crawler.NewCrawler(
+		slog.New(slog.NewTextHandler(io.Discard, nil)),
+		crawler.Options{
+			JobID:       e.cfg.JobID,
+			WorkerCount: 2,
+			MaxDepth:    e.cfg.MaxDepth,
+			Resume:      resume,
+			Scope:       crawler.Scope{Mode: crawler.ScopeHost},
+			Politeness:  crawler.Politeness{MaxConcurrency: 2},
+			Revisit:     revisit,
+		},
+		fetcher.New(5*time.Second, "justycrawler", fetcher.RetryPolicy{MaxAttempts: 1}, nil),
+		parser.New(),
+		discardStorage{},
+		e.state,
+		e.frontier,
+		nil,
+		nil,
+	)
+}
+
+func (e *testEnvironment) FrontierLen(ctx context.Context) (int, error) {
+	return e.frontier.Len(ctx)
+}
+
+func (e *testEnvironment) Clear(ctx context.Context, keepState bool) error {
+	if !keepState {
+		if err := e.state.Clear(ctx); err != nil {
+			return err
+		}
+	}
+	return e.frontier.Clear(ctx)
+}
+
+func (e *testEnvironment) Close() error { return nil }
+
+// testStores — хранилища, общие для всех менеджеров теста.
+type testStores struct {
+	mu        sync.Mutex
+	states    map[string]*visitState
+	frontiers map[string]crawler.Frontier
+	results   *memoryResults
+}
+
+func newTestStores(t *testing.T) *testStores {
+	t.Helper()
+
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	viper.Set("frontier.backend", config.FrontierMemory)
+	viper.Set("frontier.order", config.OrderBFS)
+
+	return &testStores{
+		states:    make(map[string]*visitState),
+		frontiers: make(map[string]crawler.Frontier),
+		results:   &memoryResults{runs: make(map[string]domain.Run)},
+	}
+}
+
+func (s *testStores) environment(_ context.Context, cfg *config.Config) (server.Environment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.states[cfg.JobID]; !ok {
+		s.states[cfg.JobID] = &visitState{visited: make(map[string]time.Time)}
+		s.frontiers[cfg.JobID] = crawler.NewMemoryFrontier(domain.OrderBFS)
+	}
+	return &testEnvironment{cfg: cfg, state: s.states[cfg.JobID], frontier: s.frontiers[cfg.JobID]}, nil
+}
+
+func newTestManager(t *testing.T, stores *testStores, cfg *config.Config) *server.Manager {
+	t.Helper()
+	return server.NewManager(context.Background(), cfg, stores.environment, stores.results,
+		slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+// runJob запускает задание и ждет его завершения.
+func runJob(t *testing.T, m *server.Manager, overrides map[string]any) server.JobView {
+	t.Helper()
+
+	view, err := m.Submit(overrides)
+	require.NoError(t, err)
+	return waitJob(t, m, view.ID)
+}
+
+// waitJob ждет, пока задание id завершится.
+func waitJob(t *testing.T, m *server.Manager, id string) server.JobView {
+	t.Helper()
+
+	var (
+		view server.JobView
+		err  error
+	)
+	require.Eventually(t, func() bool {
+		view, err = m.Get(id)
+		return err == nil && view.Status != server.JobRunning
+	}, 10*time.Second, 10*time.Millisecond)
+	require.Equal(t, server.JobCompleted, view.Status, view.Error)
+	return view
+}
+
+// TestManagerRerunSameJobID запускает задание с одним job_id дважды: с revisit второй
+// запуск не обходит страницы, посещенные меньше interval назад, без него обходит все заново.
+func TestManagerRerunSameJobID(t *testing.T) {
+	var fetched atomic.Int64
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetched.Add(1)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if r.URL.Path == "/" {
+			_, _ = io.WriteString(w, `<html><body><a href="/a">a</a><a href="/b">b</a></body></html>`)
+			return
+		}
+		_, _ = io.WriteString(w, `<html><body><a href="/">root</a></body></html>`)
+	}))
+	t.Cleanup(site.Close)
+
+	tests := []struct {
+		name        string
+		revisit     map[string]any
+		secondPages int64
+	}{
+		{name: "revisit", revisit: map[string]any{"interval": "1h"}, secondPages: 0},
+		{name: "без revisit", revisit: nil, secondPages: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestManager(t, newTestStores(t), &config.Config{})
+			overrides := map[string]any{"start_url": site.URL + "/", "max_depth": 1, "job_id": "docs"}
+			if tt.revisit != nil {
+				overrides["revisit"] = tt.revisit
+			}
+
+			fetched.Store(0)
+			first := runJob(t, m, overrides)
+			assert.EqualValues(t, 3, first.Stats.Pages)
+
+			fetched.Store(0)
+			second := runJob(t, m, overrides)
+			assert.Equal(t, tt.secondPages, second.Stats.Pages)
+			assert.Equal(t, tt.secondPages, fetched.Load())
+		})
+	}
+}
+
+// TestManagerRestoresPausedJob проверяет, что задание на паузе переживает перезапуск
+// сервера: новый менеджер загружает его из записей о заданиях и продолжает обход.
+func TestManagerRestoresPausedJob(t *testing.T) {
+	var fetched sync.Map
+	unblock := make(chan struct{})
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		counter, _ := fetched.LoadOrStore(r.URL.Path, new(atomic.Int64))
+		counter.(*atomic.Int64).Add(1)
+		if r.URL.Path == "/slow" {
+			select {
+			case <-unblock:
+			case <-r.Context().Done():
+				return
+			}
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = io.WriteString(w, `<html><body><a href="/slow">slow</a><a href="/a">a</a></body></html>`)
+	}))
+	t.Cleanup(site.Close)
+
+	stores := newTestStores(t)
+	first := newTestManager(t, stores, &config.Config{})
+	view, err := first.Submit(map[string]any{"start_url": site.URL + "/", "max_depth": 1})
+	require.NoError(t, err)
+
+	// Ставим на паузу, пока /slow загружается.
+	require.Eventually(t, func() bool {
+		_, ok := fetched.Load("/slow")
+		return ok
+	}, 5*time.Second, 5*time.Millisecond)
+	_, err = first.Pause(view.ID)
+	require.NoError(t, err)
+	first.Shutdown()
+	close(unblock)
+
+	second := newTestManager(t, stores, &config.Config{})
+	require.NoError(t, second.Restore(context.Background()))
+	restored, err := second.Get(view.ID)
+	require.NoError(t, err)
+	assert.Equal(t, server.JobPaused, restored.Status)
+	pending, err := second.FrontierSize(context.Background())
+	require.NoError(t, err)
+	assert.Positive(t, pending, "во фронтире задания на паузе остались задачи")
+
+	_, err = second.Resume(view.ID)
+	require.NoError(t, err)
+	done := waitJob(t, second, view.ID)
+	assert.Equal(t, server.JobCompleted, done.Status)
+	// Прерванная загрузка /slow тоже учитывается в статистике, поэтому страниц не меньше трех.
+	assert.GreaterOrEqual(t, done.Stats.Pages, int64(3))
+	for _, path := range []string{"/", "/a"} {
+		counter, ok := fetched.Load(path)
+		require.True(t, ok, path)
+		assert.EqualValuesf(t, 1, counter.(*atomic.Int64).Load(), "страница %s загружена повторно", path)
+	}
+
+	pending, err = second.FrontierSize(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, pending)
+
+	// Восстановленное задание больше не на паузе и при следующем запуске не загружается.
+	runs, err := stores.results.PausedRuns(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, runs)
+}
+
+// TestManagerResultsAfterPrune проверяет, что результаты забытого по server.job_ttl
+// задания по-прежнему доступны, а неизвестное задание дает ErrJobNotFound.
+func TestManagerResultsAfterPrune(t *testing.T) {
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = io.WriteString(w, `<html><body></body></html>`)
+	}))
+	t.Cleanup(site.Close)
+
+	cfg := &config.Config{Server: config.Server{JobTTL: time.Millisecond}}
+	m := newTestManager(t, newTestStores(t), cfg)
+	view := runJob(t, m, map[string]any{"start_url": site.URL + "/", "job_id": "docs"})
+
+	time.Sleep(5 * time.Millisecond)
+	assert.Empty(t, m.List())
+	_, err := m.Get(view.ID)
+	require.ErrorIs(t, err, server.ErrJobNotFound)
+
+	_, err = m.Results(context.Background(), view.ID, "", 0, 10)
+	require.NoError(t, err)
+	_, err = m.Results(context.Background(), "unknown", "", 0, 10)
+	require.ErrorIs(t, err, server.ErrJobNotFound)
+}
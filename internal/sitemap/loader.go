@@ -0,0 +1,234 @@
+package sitemap
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"justycrawler/internal/domain"
+	"justycrawler/internal/robots"
+
+	"golang.org/x/net/html/charset"
+)
+
+const (
+	// maxFileSize — предел протокола sitemaps.org для одного файла после распаковки.
+	maxFileSize = 50 << 20
+	// maxRobotsSize — сколько байт robots.txt читаем в поисках строк Sitemap.
+	maxRobotsSize = 500 * 1024
+	// maxIndexDepth — глубина вложенности индексов. Протокол вложенность не допускает,
+	// но на практике встречаются индексы индексов.
+	maxIndexDepth = 3
+)
+
+// Loader находит sitemap сайта и читает из них URL.
+type Loader struct {
+	client    *http.Client
+	userAgent string
+}
+
+// NewLoader создает новый Loader.
+func NewLoader(timeout time.Duration, userAgent string) *Loader {
+	return &Loader{
+		client:    &http.Client{Timeout: timeout},
+		userAgent: userAgent,
+	}
+}
+
+// Discover реализует интерфейс crawler.Sitemaps. Sitemap ищутся в строках Sitemap
+// файла robots.txt, а если их нет — по адресу /sitemap.xml. Ошибки отдельных файлов
+// возвращаются вместе с URL, которые удалось прочитать из остальных.
+func (l *Loader) Discover(ctx context.Context, siteURL string) ([]domain.SitemapEntry, error) {
+	u, err := url.Parse(siteURL)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось распарсить URL %s: %w", siteURL, err)
+	}
+	root := u.Scheme + "://" + u.Host
+
+	sitemaps, err := l.fromRobots(ctx, root+"/robots.txt")
+	if err != nil || len(sitemaps) == 0 {
+		sitemaps = []string{root + "/sitemap.xml"}
+	}
+
+	var (
+		entries []domain.SitemapEntry
+		errs    []error
+	)
+	visited := make(map[string]struct{})
+	for _, sitemapURL := range sitemaps {
+		if loadErr := l.load(ctx, sitemapURL, 0, visited, &entries); loadErr != nil {
+			errs = append(errs, loadErr)
+		}
+	}
+	return entries, errors.Join(errs...)
+}
+
+// fromRobots возвращает адреса из строк Sitemap файла robots.txt.
+func (l *Loader) fromRobots(ctx context.Context, robotsURL string) ([]string, error) {
+	body, err := l.get(ctx, robotsURL)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(body, maxRobotsSize))
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать %s: %w", robotsURL, err)
+	}
+	return robots.Parse(data).Sitemaps(), nil
+}
+
+// load читает sitemap или индекс sitemap. Вложенные sitemap загружаются после того,
+// как текущий файл прочитан, чтобы не держать открытыми несколько ответов.
+func (l *Loader) load(
+	ctx context.Context,
+	sitemapURL string,
+	depth int,
+	visited map[string]struct{},
+	entries *[]domain.SitemapEntry,
+) error {
+	if _, ok := visited[sitemapURL]; ok {
+		return nil
+	}
+	visited[sitemapURL] = struct{}{}
+
+	children, err := l.parse(ctx, sitemapURL, entries)
+	if err != nil {
+		return err
+	}
+	if len(children) > 0 && depth >= maxIndexDepth {
+		return fmt.Errorf("sitemap %s: превышена глубина вложенности индексов", sitemapURL)
+	}
+
+	var errs []error
+	for _, child := range children {
+		if loadErr := l.load(ctx, child, depth+1, visited, entries); loadErr != nil {
+			errs = append(errs, loadErr)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+type xmlURL struct {
+	Loc        string `xml:"loc"`
+	LastMod    string `xml:"lastmod"`
+	ChangeFreq string `xml:"changefreq"`
+	Priority   string `xml:"priority"`
+}
+
+type xmlSitemap struct {
+	Loc string `xml:"loc"`
+}
+
+// parse добавляет в entries URL из <urlset> и возвращает адреса из <sitemapindex>.
+func (l *Loader) parse(ctx context.Context, sitemapURL string, entries *[]domain.SitemapEntry) ([]string, error) {
+	body, err := l.get(ctx, sitemapURL)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	reader, err := decompress(io.LimitReader(body, maxFileSize))
+	if err != nil {
+		return nil, fmt.Errorf("sitemap %s: %w", sitemapURL, err)
+	}
+
+	decoder := xml.NewDecoder(reader)
+	decoder.CharsetReader = charset.NewReaderLabel
+
+	var children []string
+	for {
+		token, tokenErr := decoder.Token()
+		if errors.Is(tokenErr, io.EOF) {
+			return children, nil
+		}
+		if tokenErr != nil {
+			return children, fmt.Errorf("не удалось разобрать sitemap %s: %w", sitemapURL, tokenErr)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "url":
+			var item xmlURL
+			if err := decoder.DecodeElement(&item, &start); err != nil {
+				return children, fmt.Errorf("не удалось разобрать sitemap %s: %w", sitemapURL, err)
+			}
+			if loc := strings.TrimSpace(item.Loc); loc != "" {
+				*entries = append(*entries, domain.SitemapEntry{URL: loc, Meta: meta(sitemapURL, item)})
+			}
+		case "sitemap":
+			var item xmlSitemap
+			if err := decoder.DecodeElement(&item, &start); err != nil {
+				return children, fmt.Errorf("не удалось разобрать sitemap %s: %w", sitemapURL, err)
+			}
+			if loc := strings.TrimSpace(item.Loc); loc != "" {
+				children = append(children, loc)
+			}
+		}
+	}
+}
+
+func (l *Loader) get(ctx context.Context, rawURL string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось создать запрос к %s: %w", rawURL, err)
+	}
+	req.Header.Set("User-Agent", l.userAgent)
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось загрузить %s: %w", rawURL, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("не удалось загрузить %s: статус %d", rawURL, resp.StatusCode)
+	}
+	return resp.Body, nil
+}
+
+// decompress распаковывает gzip по сигнатуре, а не по расширению или заголовкам:
+// серверы нередко отдают sitemap.xml.gz как application/octet-stream.
+func decompress(r io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(r)
+	magic, err := buffered.Peek(2)
+	if err != nil || magic[0] != 0x1f || magic[1] != 0x8b {
+		return buffered, nil //nolint:nilerr // короткий или пустой файл разберет XML-декодер
+	}
+
+	gz, err := gzip.NewReader(buffered)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось распаковать gzip: %w", err)
+	}
+	return io.LimitReader(gz, maxFileSize), nil
+}
+
+func meta(source string, item xmlURL) domain.SitemapMeta {
+	m := domain.SitemapMeta{
+		Source:     source,
+		ChangeFreq: strings.ToLower(strings.TrimSpace(item.ChangeFreq)),
+	}
+	if priority, err := strconv.ParseFloat(strings.TrimSpace(item.Priority), 64); err == nil {
+		m.Priority = min(max(priority, 0), 1)
+	}
+	// Форматы W3C Datetime, которые допускает протокол.
+	layouts := []string{time.RFC3339Nano, "2006-01-02T15:04Z07:00", "2006-01-02", "2006-01", "2006"}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, strings.TrimSpace(item.LastMod)); err == nil {
+			m.LastMod = t
+			break
+		}
+	}
+	return m
+}
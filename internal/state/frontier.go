@@ -0,0 +1,444 @@
+package state
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"justycrawler/internal/domain"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// restoreLua — общая часть скриптов: вернуть URL в очередь. В режиме priority
+// (ARGV[3]) URL возвращается в sorted set с оценкой из описания задачи, иначе —
+// в начало списка.
+const restoreLua = `
+local function restore(url)
+	if ARGV[3] == 'priority' then
+		local score = 0
+		local raw = redis.call('HGET', KEYS[3], url)
+		if raw then
+			local ok, task = pcall(cjson.decode, raw)
+			if ok and type(task.priority) == 'number' then
+				score = task.priority
+			end
+		end
+		redis.call('ZADD', KEYS[4], score, url)
+	else
+		redis.call('LPUSH', KEYS[1], url)
+	end
+end
+`
+
+// leaseTokenBytes — длина случайного токена аренды в байтах.
+const leaseTokenBytes = 8
+
+// ErrLeaseLost — аренда задачи истекла, и задачу уже взял другой воркер.
+// Результат такой задачи не записывается во фронтир: за нее отвечает новый арендатор.
+var ErrLeaseLost = errors.New("аренда задачи истекла и передана другому воркеру")
+
+// popScript атомарно возвращает в очередь задачи с истекшей арендой, переносит
+// следующий URL из очереди в работу с арендой до ARGV[2] под токеном ARGV[4]
+// и возвращает его задачу.
+const popScript = restoreLua + `
+local expired = redis.call('ZRANGEBYSCORE', KEYS[2], '-inf', ARGV[1])
+for i = #expired, 1, -1 do
+	restore(expired[i])
+	redis.call('HDEL', KEYS[5], expired[i])
+end
+redis.call('ZREMRANGEBYSCORE', KEYS[2], '-inf', ARGV[1])
+local url
+if ARGV[3] == 'priority' then
+	url = redis.call('ZPOPMAX', KEYS[4])[1]
+else
+	url = redis.call('LPOP', KEYS[1])
+end
+if not url then
+	return false
+end
+redis.call('ZADD', KEYS[2], ARGV[2], url)
+redis.call('HSET', KEYS[5], url, ARGV[4])
+local task = redis.call('HGET', KEYS[3], url)
+return {url, task or ''}
+`
+
+// requeueScript атомарно возвращает все задачи из работы в очередь.
+const requeueScript = restoreLua + `
+local urls = redis.call('ZRANGE', KEYS[2], 0, -1)
+for i = #urls, 1, -1 do
+	restore(urls[i])
+end
+redis.call('DEL', KEYS[2], KEYS[5])
+return #urls
+`
+
+// retryScript атомарно возвращает в очередь все задачи, завершившиеся ошибкой.
+const retryScript = restoreLua + `
+local urls = redis.call('HKEYS', KEYS[6])
+for i = 1, #urls do
+	restore(urls[i])
+end
+redis.call('DEL', KEYS[6])
+return #urls
+`
+
+// doneScript завершает задачу ARGV[1], если ее аренда все еще под токеном ARGV[2].
+// Ключи: prefix:in_progress, prefix:leases, prefix:tasks, prefix:done.
+const doneScript = `
+if redis.call('HGET', KEYS[2], ARGV[1]) ~= ARGV[2] then
+	return 0
+end
+redis.call('ZREM', KEYS[1], ARGV[1])
+redis.call('HDEL', KEYS[2], ARGV[1])
+redis.call('HDEL', KEYS[3], ARGV[1])
+redis.call('SADD', KEYS[4], ARGV[1])
+return 1
+`
+
+// failScript переносит задачу ARGV[1] в неудачные, если ее аренда все еще под токеном
+// ARGV[2]: ARGV[3] — задача в JSON, ARGV[4] — причина ошибки.
+// Ключи: prefix:in_progress, prefix:leases, prefix:tasks, prefix:failed.
+const failScript = `
+if redis.call('HGET', KEYS[2], ARGV[1]) ~= ARGV[2] then
+	return 0
+end
+redis.call('ZREM', KEYS[1], ARGV[1])
+redis.call('HDEL', KEYS[2], ARGV[1])
+redis.call('HSET', KEYS[3], ARGV[1], ARGV[3])
+redis.call('HSET', KEYS[4], ARGV[1], ARGV[4])
+return 1
+`
+
+// renewScript продлевает до ARGV[1] аренды из пар «URL, токен» в ARGV[2..];
+// аренды, перешедшие к другому воркеру, не трогаются.
+// Ключи: prefix:in_progress, prefix:leases.
+const renewScript = `
+for i = 2, #ARGV, 2 do
+	if redis.call('HGET', KEYS[2], ARGV[i]) == ARGV[i + 1] then
+		redis.call('ZADD', KEYS[1], 'XX', ARGV[1], ARGV[i])
+	end
+end
+return 0
+`
+
+// RedisFrontier реализует интерфейс crawler.Frontier поверх Redis, чтобы очередь
+// обхода переживала падение и перезапуск процесса и могла делиться между несколькими
+// экземплярами краулера.
+//
+// Pop выдает задачу в аренду на visibilityTimeout под случайным токеном (Task.Lease).
+// Пока задача в работе, экземпляр продлевает аренду каждую треть visibilityTimeout,
+// поэтому долгие повторы и паузы вежливости не отдают задачу другим. Если процесс умер
+// и не вызвал Done или Fail, аренда истекает, и следующий Pop любого экземпляра вернет
+// задачу в очередь. Done и Fail с чужим или истекшим токеном возвращают ErrLeaseLost.
+//
+// Ключи (prefix — общий префикс):
+//   - prefix:tasks       — hash: URL -> задача в JSON;
+//   - prefix:queue       — list: URL в очереди для порядков bfs и dfs;
+//   - prefix:priority    — sorted set: URL в очереди для порядка priority, score — Task.Priority;
+//   - prefix:in_progress — sorted set: URL в работе, score — время окончания аренды (unix ms);
+//   - prefix:leases      — hash: URL в работе -> токен аренды;
+//   - prefix:done        — set: обработанные URL;
+//   - prefix:failed      — hash: URL -> причина ошибки.
+type RedisFrontier struct {
+	client            *redis.Client
+	pop               *redis.Script
+	requeue           *redis.Script
+	retry             *redis.Script
+	done              *redis.Script
+	fail              *redis.Script
+	renew             *redis.Script
+	visibilityTimeout time.Duration
+	order             domain.Ordering
+	logger            *slog.Logger
+
+	// held — аренды этого экземпляра: токен -> URL. Их продлевает renewLeases.
+	mu      sync.Mutex
+	held    map[string]string
+	stop    chan struct{}
+	stopped sync.WaitGroup
+
+	tasksKey      string
+	queueKey      string
+	priorityKey   string
+	inProgressKey string
+	leasesKey     string
+	doneKey       string
+	failedKey     string
+}
+
+// NewRedisFrontier создает новый экземпляр RedisFrontier, выдающий задачи в порядке order,
+// и запускает продление аренд, которое останавливает Close.
+func NewRedisFrontier(
+	ctx context.Context,
+	addr, password string,
+	db int,
+	prefix string,
+	visibilityTimeout time.Duration,
+	order domain.Ordering,
+	logger *slog.Logger,
+) (*RedisFrontier, error) {
+	if visibilityTimeout <= 0 {
+		return nil, errors.New("время аренды задачи должно быть больше нуля")
+	}
+	switch order {
+	case domain.OrderBFS, domain.OrderDFS, domain.OrderPriority:
+	case "":
+		order = domain.OrderBFS
+	default:
+		return nil, fmt.Errorf("неизвестный порядок обхода %q", order)
+	}
+
+	rdb := redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Password: password,
+		DB:       db,
+	})
+
+	pingCtx, cancel := context.WithTimeout(ctx, redisTimeout)
+	defer cancel()
+	if err := rdb.Ping(pingCtx).Err(); err != nil {
+		return nil, fmt.Errorf("не удалось подключиться к Redis: %w", err)
+	}
+
+	f := &RedisFrontier{
+		client:            rdb,
+		pop:               redis.NewScript(popScript),
+		requeue:           redis.NewScript(requeueScript),
+		retry:             redis.NewScript(retryScript),
+		done:              redis.NewScript(doneScript),
+		fail:              redis.NewScript(failScript),
+		renew:             redis.NewScript(renewScript),
+		visibilityTimeout: visibilityTimeout,
+		order:             order,
+		logger:            logger,
+		held:              make(map[string]string),
+		stop:              make(chan struct{}),
+		tasksKey:          prefix + ":tasks",
+		queueKey:          prefix + ":queue",
+		priorityKey:       prefix + ":priority",
+		inProgressKey:     prefix + ":in_progress",
+		leasesKey:         prefix + ":leases",
+		doneKey:           prefix + ":done",
+		failedKey:         prefix + ":failed",
+	}
+
+	f.stopped.Add(1)
+	go f.renewLeases()
+	return f, nil
+}
+
+// Push реализует интерфейс crawler.Frontier.
+func (f *RedisFrontier) Push(ctx context.Context, task domain.Task) error {
+	data, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("не удалось сериализовать задачу %s: %w", task.URL, err)
+	}
+
+	_, err = f.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, f.tasksKey, task.URL, data)
+		switch f.order {
+		case domain.OrderPriority:
+			pipe.ZAdd(ctx, f.priorityKey, redis.Z{Score: task.Priority, Member: task.URL})
+		case domain.OrderDFS:
+			pipe.LPush(ctx, f.queueKey, task.URL)
+		default:
+			pipe.RPush(ctx, f.queueKey, task.URL)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("ошибка добавления задачи %s во фронтир: %w", task.URL, err)
+	}
+	return nil
+}
+
+// Pop реализует интерфейс crawler.Frontier.
+func (f *RedisFrontier) Pop(ctx context.Context) (domain.Task, bool, error) {
+	token, err := newLeaseToken()
+	if err != nil {
+		return domain.Task{}, false, err
+	}
+
+	now := time.Now()
+	leaseUntil := now.Add(f.visibilityTimeout)
+	result, err := f.pop.Run(ctx, f.client, f.scriptKeys(),
+		now.UnixMilli(), leaseUntil.UnixMilli(), string(f.order), token).StringSlice()
+	if errors.Is(err, redis.Nil) {
+		return domain.Task{}, false, nil
+	}
+	if err != nil {
+		return domain.Task{}, false, fmt.Errorf("ошибка получения задачи из фронтира: %w", err)
+	}
+
+	url, raw := result[0], result[1]
+	task := domain.Task{URL: url}
+	// Пустое описание — задача потеряна, восстанавливаем хотя бы URL.
+	if raw != "" {
+		if err := json.Unmarshal([]byte(raw), &task); err != nil {
+			// Аренда остается и истечет сама: задача вернется в очередь, как у упавшего воркера.
+			return domain.Task{}, false, fmt.Errorf("не удалось разобрать задачу %s: %w", url, err)
+		}
+	}
+	task.Lease = token
+
+	f.mu.Lock()
+	f.held[token] = url
+	f.mu.Unlock()
+	return task, true, nil
+}
+
+// Done реализует интерфейс crawler.Frontier.
+func (f *RedisFrontier) Done(ctx context.Context, task domain.Task) error {
+	defer f.release(task.Lease)
+
+	keys := []string{f.inProgressKey, f.leasesKey, f.tasksKey, f.doneKey}
+	owned, err := f.done.Run(ctx, f.client, keys, task.URL, task.Lease).Int()
+	if err != nil {
+		return fmt.Errorf("ошибка завершения задачи %s: %w", task.URL, err)
+	}
+	if owned == 0 {
+		return fmt.Errorf("задача %s: %w", task.URL, ErrLeaseLost)
+	}
+	return nil
+}
+
+// Fail реализует интерфейс crawler.Frontier. Задача остается в prefix:tasks,
+// чтобы ее можно было повторить позже.
+func (f *RedisFrontier) Fail(ctx context.Context, task domain.Task, reason string) error {
+	defer f.release(task.Lease)
+
+	data, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("не удалось сериализовать задачу %s: %w", task.URL, err)
+	}
+
+	keys := []string{f.inProgressKey, f.leasesKey, f.tasksKey, f.failedKey}
+	owned, err := f.fail.Run(ctx, f.client, keys, task.URL, task.Lease, data, reason).Int()
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения неудачной задачи %s: %w", task.URL, err)
+	}
+	if owned == 0 {
+		return fmt.Errorf("задача %s: %w", task.URL, ErrLeaseLost)
+	}
+	return nil
+}
+
+// Requeue реализует интерфейс crawler.Frontier. Задачи, бывшие в работе,
+// ставятся в начало очереди, чтобы их обработали первыми (в режиме priority —
+// на место, соответствующее их оценке).
+func (f *RedisFrontier) Requeue(ctx context.Context) (int, error) {
+	// ARGV[1] и ARGV[2] скрипту не нужны, порядок передается третьим аргументом, как в Pop.
+	requeued, err := f.requeue.Run(ctx, f.client, f.scriptKeys(), 0, 0, string(f.order)).Int()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка возврата задач в очередь: %w", err)
+	}
+	return requeued, nil
+}
+
+// RetryFailed реализует интерфейс crawler.Frontier. Описания задач хранятся
+// в prefix:tasks с момента Fail, поэтому задача возвращается со всеми полями.
+func (f *RedisFrontier) RetryFailed(ctx context.Context) (int, error) {
+	keys := append(f.scriptKeys(), f.failedKey)
+	retried, err := f.retry.Run(ctx, f.client, keys, 0, 0, string(f.order)).Int()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка возврата неудачных задач в очередь: %w", err)
+	}
+	return retried, nil
+}
+
+// Len реализует интерфейс crawler.Frontier.
+func (f *RedisFrontier) Len(ctx context.Context) (int, error) {
+	var queued, prioritized, inProgress *redis.IntCmd
+	_, err := f.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		queued = pipe.LLen(ctx, f.queueKey)
+		prioritized = pipe.ZCard(ctx, f.priorityKey)
+		inProgress = pipe.ZCard(ctx, f.inProgressKey)
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("ошибка получения размера фронтира: %w", err)
+	}
+	return int(queued.Val() + prioritized.Val() + inProgress.Val()), nil
+}
+
+// Clear удаляет все ключи фронтира.
+func (f *RedisFrontier) Clear(ctx context.Context) error {
+	err := f.client.Del(ctx, f.tasksKey, f.queueKey, f.priorityKey, f.inProgressKey, f.leasesKey,
+		f.doneKey, f.failedKey).Err()
+	if err != nil {
+		return fmt.Errorf("ошибка очистки фронтира в Redis: %w", err)
+	}
+	return nil
+}
+
+// scriptKeys — ключи в порядке, который ожидают popScript и requeueScript
+// (retryScript дополнительно получает prefix:failed шестым ключом).
+func (f *RedisFrontier) scriptKeys() []string {
+	return []string{f.queueKey, f.inProgressKey, f.tasksKey, f.priorityKey, f.leasesKey}
+}
+
+// release перестает продлевать аренду: задача завершена этим экземпляром.
+func (f *RedisFrontier) release(token string) {
+	f.mu.Lock()
+	delete(f.held, token)
+	f.mu.Unlock()
+}
+
+// renewLeases продлевает аренды задач, которые еще в работе у этого экземпляра.
+// Интервал — треть visibilityTimeout, чтобы один пропущенный тик не стоил аренды.
+func (f *RedisFrontier) renewLeases() {
+	defer f.stopped.Done()
+
+	ticker := time.NewTicker(f.visibilityTimeout / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := f.renewHeld(); err != nil {
+				f.logger.Error("Не удалось продлить аренду задач", slog.Any("error", err))
+			}
+		case <-f.stop:
+			return
+		}
+	}
+}
+
+func (f *RedisFrontier) renewHeld() error {
+	f.mu.Lock()
+	args := make([]any, 0, 1+2*len(f.held))
+	args = append(args, time.Now().Add(f.visibilityTimeout).UnixMilli())
+	for token, url := range f.held {
+		args = append(args, url, token)
+	}
+	f.mu.Unlock()
+
+	if len(args) == 1 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	return f.renew.Run(ctx, f.client, []string{f.inProgressKey, f.leasesKey}, args...).Err()
+}
+
+// Close останавливает продление аренд и закрывает соединение с Redis.
+func (f *RedisFrontier) Close() error {
+	close(f.stop)
+	f.stopped.Wait()
+	return f.client.Close()
+}
+
+func newLeaseToken() (string, error) {
+	b := make([]byte, leaseTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("не удалось сгенерировать токен аренды: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,152 @@
+package state_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"justycrawler/internal/domain"
+	"justycrawler/internal/state"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPrefix = "crawler:frontier:test"
+
+func newTestFrontier(t *testing.T, addr string, visibilityTimeout time.Duration) *state.RedisFrontier {
+	t.Helper()
+
+	f, err := state.NewRedisFrontier(context.Background(), addr, "", 0, testPrefix, visibilityTimeout,
+		domain.OrderBFS, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = f.Close() })
+	return f
+}
+
+// TestRedisFrontierSharedByInstances проверяет, что экземпляры на одном Redis
+// делят очередь без повторов: каждую задачу забирает и завершает ровно один воркер.
+func TestRedisFrontierSharedByInstances(t *testing.T) {
+	mr := miniredis.RunT(t)
+	ctx := context.Background()
+
+	const (
+		instances = 3
+		workers   = 4
+		tasks     = 300
+	)
+	frontiers := make([]*state.RedisFrontier, instances)
+	for i := range frontiers {
+		frontiers[i] = newTestFrontier(t, mr.Addr(), time.Minute)
+	}
+	for i := range tasks {
+		require.NoError(t, frontiers[i%instances].Push(ctx, domain.Task{URL: fmt.Sprintf("https://example.com/%d", i)}))
+	}
+
+	var (
+		mu    sync.Mutex
+		taken = make(map[string]int)
+		wg    sync.WaitGroup
+	)
+	for _, f := range frontiers {
+		for range workers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					task, ok, err := f.Pop(ctx)
+					if !assert.NoError(t, err) || !ok {
+						return
+					}
+					assert.NotEmpty(t, task.Lease)
+
+					mu.Lock()
+					taken[task.URL]++
+					mu.Unlock()
+
+					assert.NoError(t, f.Done(ctx, task))
+				}
+			}()
+		}
+	}
+	wg.Wait()
+
+	assert.Len(t, taken, tasks)
+	for url, n := range taken {
+		assert.Equalf(t, 1, n, "задача %s выдана %d раз", url, n)
+	}
+	pending, err := frontiers[0].Len(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, pending)
+}
+
+// TestRedisFrontierRenewsLease проверяет, что аренда долгой задачи продлевается
+// и другой экземпляр не забирает ее, пока первый с ней работает.
+func TestRedisFrontierRenewsLease(t *testing.T) {
+	mr := miniredis.RunT(t)
+	ctx := context.Background()
+
+	const visibilityTimeout = 300 * time.Millisecond
+	slow := newTestFrontier(t, mr.Addr(), visibilityTimeout)
+	other := newTestFrontier(t, mr.Addr(), visibilityTimeout)
+
+	require.NoError(t, slow.Push(ctx, domain.Task{URL: "https://example.com/slow"}))
+	task, ok, err := slow.Pop(ctx)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// Задача в работе втрое дольше аренды.
+	deadline := time.Now().Add(3 * visibilityTimeout)
+	for time.Now().Before(deadline) {
+		_, stolen, popErr := other.Pop(ctx)
+		require.NoError(t, popErr)
+		require.False(t, stolen, "задачу забрал другой экземпляр, пока аренда продлевалась")
+		time.Sleep(visibilityTimeout / 5)
+	}
+
+	require.NoError(t, slow.Done(ctx, task))
+}
+
+// TestRedisFrontierRejectsLostLease проверяет, что после истечения аренды задачу
+// завершает только новый арендатор, а Done и Fail прежнего возвращают ErrLeaseLost.
+func TestRedisFrontierRejectsLostLease(t *testing.T) {
+	mr := miniredis.RunT(t)
+	ctx := context.Background()
+
+	stalled := newTestFrontier(t, mr.Addr(), time.Minute)
+	other := newTestFrontier(t, mr.Addr(), time.Minute)
+
+	const url = "https://example.com/page"
+	require.NoError(t, stalled.Push(ctx, domain.Task{URL: url}))
+	stale, ok, err := stalled.Pop(ctx)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// Аренда истекла: например, процесс надолго завис и не успел ее продлить.
+	_, err = mr.ZAdd(testPrefix+":in_progress", 0, url)
+	require.NoError(t, err)
+
+	fresh, ok, err := other.Pop(ctx)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, url, fresh.URL)
+	require.NotEqual(t, stale.Lease, fresh.Lease)
+
+	require.ErrorIs(t, stalled.Done(ctx, stale), state.ErrLeaseLost)
+	require.ErrorIs(t, stalled.Fail(ctx, stale, "timeout"), state.ErrLeaseLost)
+
+	inProgress, err := mr.ZMembers(testPrefix + ":in_progress")
+	require.NoError(t, err)
+	assert.Equal(t, []string{url}, inProgress, "прежний арендатор не должен снимать чужую аренду")
+	assert.False(t, mr.Exists(testPrefix+":failed"))
+
+	require.NoError(t, other.Done(ctx, fresh))
+	done, err := mr.SIsMember(testPrefix+":done", url)
+	require.NoError(t, err)
+	assert.True(t, done)
+}
@@ -5,21 +5,63 @@ import (
 	"fmt"
 	"time"
 
+	"justycrawler/internal/metrics"
+
 	"github.com/redis/go-redis/v9"
 )
 
 const (
 	redisTimeout = 5 * time.Second
+	// migrateBatch — сколько URL за раз переносится из старого set в sorted set.
+	migrateBatch = 1000
 )
 
+// revisitScript атомарно отмечает посещение URL (ARGV[1]) временем ARGV[2], если URL
+// еще не посещался или прошлое посещение было раньше ARGV[3]. Время — в миллисекундах Unix.
+const revisitScript = `
+local last = redis.call('ZSCORE', KEYS[1], ARGV[1])
+if last and tonumber(last) >= tonumber(ARGV[3]) then
+	return 0
+end
+redis.call('ZADD', KEYS[1], ARGV[2], ARGV[1])
+return 1
+`
+
+// legacySetScript переносит ключ KEYS[1] типа set (стейт до появления времени посещений)
+// в KEYS[2], откуда URL затем переносятся в sorted set порциями. Если KEYS[2] остался
+// от прерванной миграции, множества объединяются. Возвращает 1, если есть что переносить.
+const legacySetScript = `
+if redis.call('TYPE', KEYS[1]).ok == 'set' then
+	if redis.call('EXISTS', KEYS[2]) == 1 then
+		redis.call('SUNIONSTORE', KEYS[2], KEYS[2], KEYS[1])
+		redis.call('DEL', KEYS[1])
+	else
+		redis.call('RENAME', KEYS[1], KEYS[2])
+	end
+end
+return redis.call('EXISTS', KEYS[2])
+`
+
 // RedisState реализует интерфейс crawler.State с использованием Redis.
+// Посещенные URL хранятся в sorted set setKey со временем последнего посещения
+// в качестве score.
 type RedisState struct {
-	client *redis.Client
-	setKey string
+	client  *redis.Client
+	revisit *redis.Script
+	setKey  string
+	metrics *metrics.Metrics
 }
 
-// NewRedisState создает новый экземпляр RedisState.
-func NewRedisState(ctx context.Context, addr, password string, db int, setKey string) (*RedisState, error) {
+// NewRedisState создает новый экземпляр RedisState. Метрики m могут быть nil.
+// Стейт прошлых версий (обычный set) переводится в sorted set, история посещений
+// при этом сохраняется.
+func NewRedisState(
+	ctx context.Context,
+	addr, password string,
+	db int,
+	setKey string,
+	m *metrics.Metrics,
+) (*RedisState, error) {
 	rdb := redis.NewClient(&redis.Options{
 		Addr:     addr,
 		Password: password,
@@ -32,25 +74,93 @@ func NewRedisState(ctx context.Context, addr, password string, db int, setKey st
 		return nil, fmt.Errorf("не удалось подключиться к Redis: %w", err)
 	}
 
-	return &RedisState{
-		client: rdb,
-		setKey: setKey,
-	}, nil
+	s := &RedisState{
+		client:  rdb,
+		revisit: redis.NewScript(revisitScript),
+		setKey:  setKey,
+		metrics: m,
+	}
+	if err := s.migrateLegacySet(ctx); err != nil {
+		_ = rdb.Close()
+		return nil, err
+	}
+	return s, nil
 }
 
-// Add атомарно добавляет URL в множество в Redis.
-func (s *RedisState) Add(ctx context.Context, url string) (bool, error) {
-	result, err := s.client.SAdd(ctx, s.setKey, url).Result()
+// Add реализует интерфейс crawler.State.
+func (s *RedisState) Add(ctx context.Context, url string, staleBefore time.Time) (bool, error) {
+	now := time.Now()
+	if staleBefore.IsZero() {
+		// URL обходится один раз: время прошлого посещения не важно.
+		added, err := s.client.ZAddNX(ctx, s.setKey, redis.Z{Score: float64(now.UnixMilli()), Member: url}).Result()
+		if err != nil {
+			s.metrics.Error(metrics.StageState)
+			return false, fmt.Errorf("ошибка выполнения команды ZADD в Redis: %w", err)
+		}
+		// Если added == 1, значит, элемент был новым.
+		return added > 0, nil
+	}
+
+	added, err := s.revisit.Run(ctx, s.client, []string{s.setKey},
+		url, now.UnixMilli(), staleBefore.UnixMilli()).Int()
 	if err != nil {
-		return false, fmt.Errorf("ошибка выполнения команды SADD в Redis: %w", err)
+		s.metrics.Error(metrics.StageState)
+		return false, fmt.Errorf("ошибка отметки посещения URL в Redis: %w", err)
 	}
-	// Если result == 1, значит, элемент был новым.
-	return result > 0, nil
+	return added > 0, nil
+}
+
+// migrateLegacySet переносит URL из set прошлых версий в sorted set с нулевым временем
+// посещения: они считаются давно посещенными, и revisit обойдет их при первом запуске.
+// Перенос идет порциями, чтобы не блокировать Redis на больших множествах; прерванная
+// миграция продолжится при следующем запуске.
+func (s *RedisState) migrateLegacySet(ctx context.Context) error {
+	legacyKey := s.legacyKey()
+	pending, err := redis.NewScript(legacySetScript).Run(ctx, s.client, []string{s.setKey, legacyKey}).Int()
+	if err != nil {
+		return fmt.Errorf("не удалось проверить тип ключа стейта %s: %w", s.setKey, err)
+	}
+	if pending == 0 {
+		return nil
+	}
+
+	var cursor uint64
+	for {
+		urls, next, err := s.client.SScan(ctx, legacyKey, cursor, "", migrateBatch).Result()
+		if err != nil {
+			return fmt.Errorf("не удалось прочитать старый стейт %s: %w", legacyKey, err)
+		}
+		if len(urls) > 0 {
+			members := make([]redis.Z, 0, len(urls))
+			for _, url := range urls {
+				members = append(members, redis.Z{Score: 0, Member: url})
+			}
+			// NX: посещение, отмеченное уже после начала миграции, важнее.
+			if err := s.client.ZAddNX(ctx, s.setKey, members...).Err(); err != nil {
+				return fmt.Errorf("не удалось перенести старый стейт в %s: %w", s.setKey, err)
+			}
+		}
+		if next == 0 {
+			break
+		}
+		cursor = next
+	}
+
+	if err := s.client.Del(ctx, legacyKey).Err(); err != nil {
+		return fmt.Errorf("не удалось удалить старый стейт %s: %w", legacyKey, err)
+	}
+	return nil
+}
+
+// legacyKey — ключ, в котором set прошлых версий ждет переноса в sorted set.
+func (s *RedisState) legacyKey() string {
+	return s.setKey + ":legacy_set"
 }
 
 // Clear удаляет ключ состояния из Redis.
 func (s *RedisState) Clear(ctx context.Context) error {
-	if err := s.client.Del(ctx, s.setKey).Err(); err != nil {
+	if err := s.client.Del(ctx, s.setKey, s.legacyKey()).Err(); err != nil {
+		s.metrics.Error(metrics.StageState)
 		return fmt.Errorf("ошибка выполнения команды DEL в Redis для ключа %s: %w", s.setKey, err)
 	}
 	return nil
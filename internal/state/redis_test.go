@@ -0,0 +1,44 @@
+package state_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"justycrawler/internal/state"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSetKey = "crawler:visited_urls:test"
+
+// TestRedisStateMigratesLegacySet проверяет, что set прошлых версий переводится в sorted set:
+// без revisit URL остаются посещенными, с revisit считаются давно посещенными.
+func TestRedisStateMigratesLegacySet(t *testing.T) {
+	mr := miniredis.RunT(t)
+	ctx := context.Background()
+
+	_, err := mr.SetAdd(testSetKey, "https://example.com/a", "https://example.com/b")
+	require.NoError(t, err)
+
+	s, err := state.NewRedisState(ctx, mr.Addr(), "", 0, testSetKey, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = s.Close() })
+
+	assert.Equal(t, "zset", mr.Type(testSetKey))
+	assert.False(t, mr.Exists(testSetKey+":legacy_set"))
+
+	added, err := s.Add(ctx, "https://example.com/a", time.Time{})
+	require.NoError(t, err)
+	assert.False(t, added, "URL из старого стейта уже посещен")
+
+	added, err = s.Add(ctx, "https://example.com/b", time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.True(t, added, "URL из старого стейта пора посетить снова")
+
+	added, err = s.Add(ctx, "https://example.com/b", time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.False(t, added, "URL только что посещен")
+}
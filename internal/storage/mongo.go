@@ -2,11 +2,15 @@ package storage
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"justycrawler/internal/domain"
+	"justycrawler/internal/metrics"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -19,10 +23,18 @@ const (
 type MongoStorage struct {
 	client     *mongo.Client
 	collection *mongo.Collection
+	runs       *mongo.Collection
+	metrics    *metrics.Metrics
 }
 
-// NewMongoStorage создает и возвращает новый экземпляр MongoStorage.
-func NewMongoStorage(ctx context.Context, uri, dbName, collectionName string) (*MongoStorage, error) {
+// NewMongoStorage создает и возвращает новый экземпляр MongoStorage. Результаты
+// хранятся в коллекции collectionName, записи о запусках — в runsCollection.
+// Метрики m могут быть nil.
+func NewMongoStorage(
+	ctx context.Context,
+	uri, dbName, collectionName, runsCollection string,
+	m *metrics.Metrics,
+) (*MongoStorage, error) {
 	ctx, cancel := context.WithTimeout(ctx, mongoTimeout)
 	defer cancel()
 
@@ -35,22 +47,181 @@ func NewMongoStorage(ctx context.Context, uri, dbName, collectionName string) (*
 	}
 
 	collection := client.Database(dbName).Collection(collectionName)
+	// Результат однозначно определяется обходом и URL: обходы пересекающихся
+	// сайтов не перезаписывают документы друг друга.
+	_, err = collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "job_id", Value: 1}, {Key: "url", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("не удалось создать индекс результатов: %w", err)
+	}
+
 	return &MongoStorage{
 		client:     client,
 		collection: collection,
+		runs:       client.Database(dbName).Collection(runsCollection),
+		metrics:    m,
 	}, nil
 }
 
 // Save реализует метод сохранения данных в MongoDB.
+// Документ заменяется целиком, чтобы от прошлого обхода не оставались устаревшие поля
+// (например, цепочка редиректов, которой больше нет).
 func (s *MongoStorage) Save(ctx context.Context, data domain.CrawledData) error {
-	filter := bson.M{"url": data.URL}
-	update := bson.M{"$set": data}
-	opts := options.Update().SetUpsert(true)
+	filter := bson.M{"job_id": data.JobID, "url": data.URL}
+	opts := options.Replace().SetUpsert(true)
 
-	_, err := s.collection.UpdateOne(ctx, filter, update, opts)
+	_, err := s.collection.ReplaceOne(ctx, filter, data, opts)
+	if err != nil {
+		s.metrics.Error(metrics.StageStorage)
+	}
 	return err
 }
 
+// Find возвращает результаты задания jobID в порядке сохранения: не больше limit
+// документов, пропустив первые skip. Пустой status — документы с любым статусом.
+// HTML, содержимое страниц, заголовки и ссылки не загружаются.
+func (s *MongoStorage) Find(
+	ctx context.Context,
+	jobID, status string,
+	skip, limit int64,
+) ([]domain.CrawledData, error) {
+	filter := bson.M{"job_id": jobID}
+	if status != "" {
+		filter["status"] = status
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetSkip(skip).
+		SetLimit(limit).
+		SetProjection(bson.M{"html": 0, "page": 0, "headers": 0, "links": 0})
+
+	cursor, err := s.collection.Find(ctx, filter, opts)
+	if err != nil {
+		s.metrics.Error(metrics.StageStorage)
+		return nil, fmt.Errorf("ошибка поиска результатов задания %s: %w", jobID, err)
+	}
+	results := make([]domain.CrawledData, 0, limit)
+	if err := cursor.All(ctx, &results); err != nil {
+		s.metrics.Error(metrics.StageStorage)
+		return nil, fmt.Errorf("ошибка чтения результатов задания %s: %w", jobID, err)
+	}
+	return results, nil
+}
+
+// Previous реализует интерфейс crawler.History. HTML и содержимое страницы не загружаются.
+func (s *MongoStorage) Previous(ctx context.Context, jobID, url string) (domain.CrawledData, bool, error) {
+	opts := options.FindOne().SetProjection(bson.M{"html": 0, "page": 0, "headers": 0})
+
+	var data domain.CrawledData
+	err := s.collection.FindOne(ctx, bson.M{"job_id": jobID, "url": url}, opts).Decode(&data)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return domain.CrawledData{}, false, nil
+	}
+	if err != nil {
+		s.metrics.Error(metrics.StageStorage)
+		return domain.CrawledData{}, false, fmt.Errorf("ошибка чтения прошлого результата %s: %w", url, err)
+	}
+	return data, true, nil
+}
+
+// MarkUnchanged реализует интерфейс crawler.History.
+func (s *MongoStorage) MarkUnchanged(ctx context.Context, jobID, url string, checkedAt time.Time) error {
+	filter := bson.M{"job_id": jobID, "url": url}
+	update := bson.M{"$set": bson.M{"last_checked": checkedAt}}
+	if _, err := s.collection.UpdateOne(ctx, filter, update); err != nil {
+		s.metrics.Error(metrics.StageStorage)
+		return fmt.Errorf("ошибка отметки проверки %s: %w", url, err)
+	}
+	return nil
+}
+
+// SaveRun сохраняет запись о запуске обхода. При первом сохранении записи
+// присваивается идентификатор, повторные сохранения обновляют ее.
+func (s *MongoStorage) SaveRun(ctx context.Context, run *domain.Run) error {
+	if run.ID == "" {
+		run.ID = primitive.NewObjectID().Hex()
+	}
+
+	_, err := s.runs.ReplaceOne(ctx, bson.M{"_id": run.ID}, run, options.Replace().SetUpsert(true))
+	if err != nil {
+		s.metrics.Error(metrics.StageStorage)
+		return fmt.Errorf("ошибка сохранения записи о запуске %s: %w", run.ID, err)
+	}
+	return nil
+}
+
+// LastRun возвращает последнюю запись о запуске обхода jobID.
+func (s *MongoStorage) LastRun(ctx context.Context, jobID string) (domain.Run, bool, error) {
+	opts := options.FindOne().SetSort(bson.D{{Key: "started_at", Value: -1}})
+
+	var run domain.Run
+	err := s.runs.FindOne(ctx, bson.M{"job_id": jobID}, opts).Decode(&run)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return domain.Run{}, false, nil
+	}
+	if err != nil {
+		s.metrics.Error(metrics.StageStorage)
+		return domain.Run{}, false, fmt.Errorf("ошибка чтения записи о задании %s: %w", jobID, err)
+	}
+	return run, true, nil
+}
+
+// PausedRuns возвращает записи о заданиях сервера, оставшихся на паузе, новые первыми.
+// Вложенные документы настроек задания приводятся к обычным map и slice, чтобы
+// их можно было снова передать в конфиг.
+func (s *MongoStorage) PausedRuns(ctx context.Context) ([]domain.Run, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "started_at", Value: -1}})
+	cursor, err := s.runs.Find(ctx, bson.M{"status": domain.RunPaused}, opts)
+	if err != nil {
+		s.metrics.Error(metrics.StageStorage)
+		return nil, fmt.Errorf("ошибка поиска заданий на паузе: %w", err)
+	}
+
+	var runs []domain.Run
+	if err := cursor.All(ctx, &runs); err != nil {
+		s.metrics.Error(metrics.StageStorage)
+		return nil, fmt.Errorf("ошибка чтения заданий на паузе: %w", err)
+	}
+	for i := range runs {
+		if overrides, ok := plainValue(runs[i].Overrides).(map[string]any); ok {
+			runs[i].Overrides = overrides
+		}
+	}
+	return runs, nil
+}
+
+// plainValue заменяет документы и массивы BSON на map[string]any и []any.
+func plainValue(v any) any {
+	switch v := v.(type) {
+	case primitive.D:
+		m := make(map[string]any, len(v))
+		for _, e := range v {
+			m[e.Key] = plainValue(e.Value)
+		}
+		return m
+	case primitive.M:
+		return plainValue(map[string]any(v))
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for key, value := range v {
+			m[key] = plainValue(value)
+		}
+		return m
+	case primitive.A:
+		return plainValue([]any(v))
+	case []any:
+		a := make([]any, len(v))
+		for i, value := range v {
+			a[i] = plainValue(value)
+		}
+		return a
+	default:
+		return v
+	}
+}
+
 // Close закрывает соединение с MongoDB.
 func (s *MongoStorage) Close(ctx context.Context) error {
 	return s.client.Disconnect(ctx)
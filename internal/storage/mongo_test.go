@@ -0,0 +1,61 @@
+package storage_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"justycrawler/internal/domain"
+	"justycrawler/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TestCrawledDataKeepsEmptyJobID проверяет, что пустой job_id сохраняется в документе:
+// иначе фильтр Save {job_id: "", url} не нашел бы документ, сохраненный ранее.
+func TestCrawledDataKeepsEmptyJobID(t *testing.T) {
+	raw, err := bson.Marshal(domain.CrawledData{URL: "https://example.com/"})
+	require.NoError(t, err)
+
+	value, err := bson.Raw(raw).LookupErr("job_id")
+	require.NoError(t, err, "в документе нет поля job_id")
+	assert.Equal(t, "", value.StringValue())
+}
+
+// TestMongoStorageSaveTwiceWithoutJobID сохраняет один URL дважды без job_id: второй раз
+// документ должен замениться, а не вставиться заново и упереться в уникальный индекс.
+// Нужен MongoDB, адрес задается переменной MONGO_TEST_URI.
+func TestMongoStorageSaveTwiceWithoutJobID(t *testing.T) {
+	uri := os.Getenv("MONGO_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGO_TEST_URI не задан")
+	}
+	ctx := context.Background()
+
+	database := fmt.Sprintf("crawler_test_%d", time.Now().UnixNano())
+	s, err := storage.NewMongoStorage(ctx, uri, database, "links", "runs", nil)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = s.Close(context.Background())
+		client, connErr := mongo.Connect(context.Background(), options.Client().ApplyURI(uri))
+		if connErr == nil {
+			_ = client.Database(database).Drop(context.Background())
+			_ = client.Disconnect(context.Background())
+		}
+	})
+
+	const url = "https://example.com/"
+	require.NoError(t, s.Save(ctx, domain.CrawledData{URL: url, Status: domain.StatusCrawled}))
+	require.NoError(t, s.Save(ctx, domain.CrawledData{URL: url, Status: domain.StatusFailed}))
+
+	results, err := s.Find(ctx, "", "", 0, 10)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, domain.StatusFailed, results[0].Status)
+}
@@ -0,0 +1,218 @@
+// Package urlnorm приводит URL к единому виду, чтобы одна и та же страница,
+// записанная по-разному, не обходилась и не сохранялась дважды.
+package urlnorm
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Normalizer приводит URL к каноническому виду и вырезает параметры отслеживания.
+type Normalizer struct {
+	stripExact    map[string]struct{}
+	stripPrefixes []string
+}
+
+// New создает Normalizer. stripParams — имена query-параметров, которые нужно удалять,
+// без учета регистра; «*» в конце означает префикс (например, «utm_*»).
+func New(stripParams []string) *Normalizer {
+	n := &Normalizer{stripExact: make(map[string]struct{}, len(stripParams))}
+	for _, param := range stripParams {
+		param = strings.ToLower(strings.TrimSpace(param))
+		if prefix, ok := strings.CutSuffix(param, "*"); ok {
+			n.stripPrefixes = append(n.stripPrefixes, prefix)
+			continue
+		}
+		n.stripExact[param] = struct{}{}
+	}
+	return n
+}
+
+// Normalize возвращает нормализованный URL:
+//   - схема и хост в нижнем регистре, порт по умолчанию удален;
+//   - сегменты «.» и «..» разрешены, пустой путь заменен на «/»;
+//   - в percent-кодировке hex в верхнем регистре, незарезервированные символы раскодированы;
+//   - параметры отслеживания удалены, остальные отсортированы;
+//   - фрагмент удален.
+func (n *Normalizer) Normalize(rawURL string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", fmt.Errorf("не удалось распарсить URL %s: %w", rawURL, err)
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = normalizeHost(u.Scheme, u.Host)
+	u.Fragment = ""
+	u.RawFragment = ""
+
+	escapedPath := removeDotSegments(normalizePercent(u.EscapedPath()))
+	if escapedPath == "" && u.Host != "" {
+		escapedPath = "/"
+	}
+	u.Path, err = url.PathUnescape(escapedPath)
+	if err != nil {
+		return "", fmt.Errorf("некорректный путь в URL %s: %w", rawURL, err)
+	}
+	u.RawPath = escapedPath
+
+	u.RawQuery = n.normalizeQuery(u.RawQuery)
+	u.ForceQuery = false
+
+	return u.String(), nil
+}
+
+func normalizeHost(scheme, host string) string {
+	host = strings.ToLower(host)
+	hostname, port, found := strings.Cut(host, ":")
+	// IPv6 вида [::1]:8080 — порт после последнего двоеточия за скобкой.
+	if strings.HasPrefix(host, "[") {
+		idx := strings.LastIndex(host, "]:")
+		if idx < 0 {
+			return host
+		}
+		hostname, port, found = host[:idx+1], host[idx+2:], true
+	}
+
+	hostname = strings.TrimSuffix(hostname, ".")
+	if !found || port == "" || port == defaultPort(scheme) {
+		return hostname
+	}
+	return hostname + ":" + port
+}
+
+// defaultPort возвращает порт, который можно не указывать для схемы.
+func defaultPort(scheme string) string {
+	switch scheme {
+	case "http":
+		return "80"
+	case "https":
+		return "443"
+	default:
+		return ""
+	}
+}
+
+// normalizeQuery удаляет параметры отслеживания и сортирует остальные.
+// Значения не перекодируются, чтобы не путать «+» и «%20».
+func (n *Normalizer) normalizeQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+
+	params := strings.Split(rawQuery, "&")
+	kept := params[:0]
+	for _, param := range params {
+		if param == "" {
+			continue
+		}
+		key, _, _ := strings.Cut(param, "=")
+		if decoded, err := url.QueryUnescape(key); err == nil {
+			key = decoded
+		}
+		if n.shouldStrip(strings.ToLower(key)) {
+			continue
+		}
+		kept = append(kept, normalizePercent(param))
+	}
+
+	sort.SliceStable(kept, func(i, j int) bool {
+		keyI, valueI, _ := strings.Cut(kept[i], "=")
+		keyJ, valueJ, _ := strings.Cut(kept[j], "=")
+		if keyI != keyJ {
+			return keyI < keyJ
+		}
+		return valueI < valueJ
+	})
+	return strings.Join(kept, "&")
+}
+
+func (n *Normalizer) shouldStrip(key string) bool {
+	if _, ok := n.stripExact[key]; ok {
+		return true
+	}
+	for _, prefix := range n.stripPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizePercent раскодирует незарезервированные символы (RFC 3986, 2.3)
+// и переводит hex остальных escape-последовательностей в верхний регистр.
+func normalizePercent(s string) string {
+	if !strings.Contains(s, "%") {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' || i+2 >= len(s) || !isHex(s[i+1]) || !isHex(s[i+2]) {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		c := unhex(s[i+1])<<4 | unhex(s[i+2])
+		if isUnreserved(c) {
+			b.WriteByte(c)
+		} else {
+			b.WriteByte('%')
+			b.WriteString(strings.ToUpper(s[i+1 : i+3]))
+		}
+		i += 2
+	}
+	return b.String()
+}
+
+// removeDotSegments разрешает «.» и «..» в пути (RFC 3986, 5.2.4), сохраняя завершающий «/».
+func removeDotSegments(p string) string {
+	if !strings.Contains(p, ".") {
+		return p
+	}
+
+	segments := strings.Split(p, "/")
+	out := make([]string, 0, len(segments))
+	for i, segment := range segments {
+		isLast := i == len(segments)-1
+		switch segment {
+		case ".":
+			if isLast {
+				out = append(out, "")
+			}
+		case "..":
+			// Первый элемент — пустая строка перед ведущим «/», его не удаляем.
+			if len(out) > 1 {
+				out = out[:len(out)-1]
+			}
+			if isLast {
+				out = append(out, "")
+			}
+		default:
+			out = append(out, segment)
+		}
+	}
+	return strings.Join(out, "/")
+}
+
+func isHex(c byte) bool {
+	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
+
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
+
+func isUnreserved(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
@@ -0,0 +1,123 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	crawler "justycrawler/internal/app/crawler"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Environment is an autogenerated mock type for the Environment type
+type Environment struct {
+	mock.Mock
+}
+
+// Clear provides a mock function with given fields: ctx, keepState
+func (_m *Environment) Clear(ctx context.Context, keepState bool) error {
+	ret := _m.Called(ctx, keepState)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Clear")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, bool) error); ok {
+		r0 = rf(ctx, keepState)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Close provides a mock function with no fields
+func (_m *Environment) Close() error {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Close")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Crawler provides a mock function with given fields: resume
+func (_m *Environment) Crawler(resume bool) (*crawler.Crawler, error) {
+	ret := _m.Called(resume)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Crawler")
+	}
+
+	var r0 *crawler.Crawler
+	var r1 error
+	if rf, ok := ret.Get(0).(func(bool) (*crawler.Crawler, error)); ok {
+		return rf(resume)
+	}
+	if rf, ok := ret.Get(0).(func(bool) *crawler.Crawler); ok {
+		r0 = rf(resume)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*crawler.Crawler)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(bool) error); ok {
+		r1 = rf(resume)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FrontierLen provides a mock function with given fields: ctx
+func (_m *Environment) FrontierLen(ctx context.Context) (int, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FrontierLen")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewEnvironment creates a new instance of Environment. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewEnvironment(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Environment {
+	mock := &Environment{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -5,7 +5,7 @@ package mocks
 import (
 	context "context"
 
-	io "io"
+	domain "justycrawler/internal/domain"
 
 	mock "github.com/stretchr/testify/mock"
 )
@@ -15,29 +15,29 @@ type Fetcher struct {
 	mock.Mock
 }
 
-// Fetch provides a mock function with given fields: ctx, url
-func (_m *Fetcher) Fetch(ctx context.Context, url string) (io.ReadCloser, error) {
-	ret := _m.Called(ctx, url)
+// Fetch provides a mock function with given fields: ctx, url, validators, pace
+func (_m *Fetcher) Fetch(ctx context.Context, url string, validators domain.Validators, pace domain.Pace) (*domain.FetchResult, error) {
+	ret := _m.Called(ctx, url, validators, pace)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Fetch")
 	}
 
-	var r0 io.ReadCloser
+	var r0 *domain.FetchResult
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) (io.ReadCloser, error)); ok {
-		return rf(ctx, url)
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.Validators, domain.Pace) (*domain.FetchResult, error)); ok {
+		return rf(ctx, url, validators, pace)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) io.ReadCloser); ok {
-		r0 = rf(ctx, url)
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.Validators, domain.Pace) *domain.FetchResult); ok {
+		r0 = rf(ctx, url, validators, pace)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(io.ReadCloser)
+			r0 = ret.Get(0).(*domain.FetchResult)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, url)
+	if rf, ok := ret.Get(1).(func(context.Context, string, domain.Validators, domain.Pace) error); ok {
+		r1 = rf(ctx, url, validators, pace)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -0,0 +1,84 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "justycrawler/internal/domain"
+	time "time"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// History is an autogenerated mock type for the History type
+type History struct {
+	mock.Mock
+}
+
+// MarkUnchanged provides a mock function with given fields: ctx, jobID, url, checkedAt
+func (_m *History) MarkUnchanged(ctx context.Context, jobID string, url string, checkedAt time.Time) error {
+	ret := _m.Called(ctx, jobID, url, checkedAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkUnchanged")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Time) error); ok {
+		r0 = rf(ctx, jobID, url, checkedAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Previous provides a mock function with given fields: ctx, jobID, url
+func (_m *History) Previous(ctx context.Context, jobID string, url string) (domain.CrawledData, bool, error) {
+	ret := _m.Called(ctx, jobID, url)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Previous")
+	}
+
+	var r0 domain.CrawledData
+	var r1 bool
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (domain.CrawledData, bool, error)); ok {
+		return rf(ctx, jobID, url)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) domain.CrawledData); ok {
+		r0 = rf(ctx, jobID, url)
+	} else {
+		r0 = ret.Get(0).(domain.CrawledData)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) bool); ok {
+		r1 = rf(ctx, jobID, url)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, string) error); ok {
+		r2 = rf(ctx, jobID, url)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// NewHistory creates a new instance of History. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewHistory(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *History {
+	mock := &History{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -2,31 +2,35 @@
 
 package mocks
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	domain "justycrawler/internal/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
 
 // Parser is an autogenerated mock type for the Parser type
 type Parser struct {
 	mock.Mock
 }
 
-// ParseLinks provides a mock function with given fields: baseRawURL, htmlBody
-func (_m *Parser) ParseLinks(baseRawURL string, htmlBody []byte) ([]string, error) {
+// ParsePage provides a mock function with given fields: baseRawURL, htmlBody
+func (_m *Parser) ParsePage(baseRawURL string, htmlBody []byte) (*domain.Page, error) {
 	ret := _m.Called(baseRawURL, htmlBody)
 
 	if len(ret) == 0 {
-		panic("no return value specified for ParseLinks")
+		panic("no return value specified for ParsePage")
 	}
 
-	var r0 []string
+	var r0 *domain.Page
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, []byte) ([]string, error)); ok {
+	if rf, ok := ret.Get(0).(func(string, []byte) (*domain.Page, error)); ok {
 		return rf(baseRawURL, htmlBody)
 	}
-	if rf, ok := ret.Get(0).(func(string, []byte) []string); ok {
+	if rf, ok := ret.Get(0).(func(string, []byte) *domain.Page); ok {
 		r0 = rf(baseRawURL, htmlBody)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]string)
+			r0 = ret.Get(0).(*domain.Page)
 		}
 	}
 
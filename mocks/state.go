@@ -5,6 +5,8 @@ package mocks
 import (
 	context "context"
 
+	time "time"
+
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -13,9 +15,9 @@ type State struct {
 	mock.Mock
 }
 
-// Add provides a mock function with given fields: ctx, url
-func (_m *State) Add(ctx context.Context, url string) (bool, error) {
-	ret := _m.Called(ctx, url)
+// Add provides a mock function with given fields: ctx, url, staleBefore
+func (_m *State) Add(ctx context.Context, url string, staleBefore time.Time) (bool, error) {
+	ret := _m.Called(ctx, url, staleBefore)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Add")
@@ -23,17 +25,17 @@ func (_m *State) Add(ctx context.Context, url string) (bool, error) {
 
 	var r0 bool
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) (bool, error)); ok {
-		return rf(ctx, url)
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) (bool, error)); ok {
+		return rf(ctx, url, staleBefore)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
-		r0 = rf(ctx, url)
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) bool); ok {
+		r0 = rf(ctx, url, staleBefore)
 	} else {
 		r0 = ret.Get(0).(bool)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, url)
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time) error); ok {
+		r1 = rf(ctx, url, staleBefore)
 	} else {
 		r1 = ret.Error(1)
 	}